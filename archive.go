@@ -0,0 +1,797 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// archiveVersion is bumped whenever the archive layout changes in a way
+// older readers can't handle. Version 2 added tags, rules, saved searches
+// and preferences; version 1 archives still restore.
+const archiveVersion = 2
+
+const (
+	archiveJSON  = "json"
+	archiveJSONL = "jsonl"
+)
+
+// archive is a JSON snapshot of the database, keeping IDs and timestamps
+// so it can be restored as-is. Sessions are left out on purpose: restoring
+// an archive must not log anybody in.
+type archive struct {
+	Version        int                  `json:"version"`
+	CreatedAt      time.Time            `json:"created_at"`
+	Users          []archiveUser        `json:"users"`
+	Feeds          []archiveFeed        `json:"feeds"`
+	FeedFollows    []archiveFollow      `json:"feed_follows"`
+	Tags           []archiveTag         `json:"tags"`
+	FeedFollowTags []archiveFollowTag   `json:"feed_follow_tags"`
+	Posts          []archivePost        `json:"posts"`
+	PostStates     []archivePostState   `json:"post_states"`
+	PostRules      []archivePostRule    `json:"post_rules"`
+	SavedSearches  []archiveSavedSearch `json:"saved_searches"`
+	Preferences    []archivePreference  `json:"preferences"`
+}
+
+type archiveUser struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Name          string     `json:"name"`
+	PasswordHash  *string    `json:"password_hash"`
+	DeactivatedAt *time.Time `json:"deactivated_at"`
+	Role          string     `json:"role"`
+}
+
+type archiveFeed struct {
+	ID            uuid.UUID  `json:"id"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	AddedBy       *uuid.UUID `json:"added_by"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+	// RetentionDays and RetentionMaxPosts are the feed's own retention
+	// limits; nil means the default applies.
+	RetentionDays     *int32 `json:"retention_days,omitempty"`
+	RetentionMaxPosts *int32 `json:"retention_max_posts,omitempty"`
+}
+
+type archiveFollow struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	FeedID    uuid.UUID `json:"feed_id"`
+}
+
+type archiveTag struct {
+	ID        uuid.UUID `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	UserID    uuid.UUID `json:"user_id"`
+	Name      string    `json:"name"`
+}
+
+type archiveFollowTag struct {
+	FeedFollowID uuid.UUID `json:"feed_follow_id"`
+	TagID        uuid.UUID `json:"tag_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type archivePost struct {
+	ID           uuid.UUID  `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	Title        string     `json:"title"`
+	URL          string     `json:"url"`
+	Description  *string    `json:"description"`
+	PublishedAt  *time.Time `json:"published_at"`
+	FeedID       uuid.UUID  `json:"feed_id"`
+	Author       *string    `json:"author"`
+	Content      *string    `json:"content"`
+	Categories   []string   `json:"categories"`
+	CanonicalURL *string    `json:"canonical_url"`
+	Fingerprint  *string    `json:"fingerprint"`
+	ClusterID    uuid.UUID  `json:"cluster_id"`
+}
+
+type archivePostState struct {
+	UserID    uuid.UUID  `json:"user_id"`
+	PostID    uuid.UUID  `json:"post_id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	ReadAt    *time.Time `json:"read_at"`
+	StarredAt *time.Time `json:"starred_at"`
+}
+
+type archivePostRule struct {
+	ID        uuid.UUID  `json:"id"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	UserID    uuid.UUID  `json:"user_id"`
+	FeedID    *uuid.UUID `json:"feed_id"`
+	Field     string     `json:"field"`
+	MatchType string     `json:"match_type"`
+	Pattern   string     `json:"pattern"`
+	Action    string     `json:"action"`
+}
+
+type archiveSavedSearch struct {
+	ID            uuid.UUID   `json:"id"`
+	CreatedAt     time.Time   `json:"created_at"`
+	UpdatedAt     time.Time   `json:"updated_at"`
+	UserID        uuid.UUID   `json:"user_id"`
+	Name          string      `json:"name"`
+	Query         *string     `json:"query"`
+	FeedIDs       []uuid.UUID `json:"feed_ids"`
+	Tags          []string    `json:"tags"`
+	Author        *string     `json:"author"`
+	WindowSeconds *int64      `json:"window_seconds"`
+}
+
+type archivePreference struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// buildArchive reads every table into an archive.
+func buildArchive(s *state) (archive, error) {
+	ctx := context.Background()
+	a := archive{Version: archiveVersion, CreatedAt: time.Now().UTC()}
+
+	users, err := s.db.GetUsers(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get users: %w", err)
+	}
+	for _, u := range users {
+		a.Users = append(a.Users, archiveUser{
+			ID:            u.ID,
+			CreatedAt:     u.CreatedAt,
+			UpdatedAt:     u.UpdatedAt,
+			Name:          u.Name,
+			PasswordHash:  nullStringPtr(u.PasswordHash),
+			DeactivatedAt: nullTimePtr(u.DeactivatedAt),
+			Role:          u.Role,
+		})
+	}
+
+	feeds, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get feeds: %w", err)
+	}
+	for _, f := range feeds {
+		a.Feeds = append(a.Feeds, archiveFeed{
+			ID:                f.ID,
+			CreatedAt:         f.CreatedAt,
+			UpdatedAt:         f.UpdatedAt,
+			Name:              f.Name,
+			URL:               f.Url,
+			AddedBy:           nullUUIDPtr(f.AddedBy),
+			LastFetchedAt:     nullTimePtr(f.LastFetchedAt),
+			RetentionDays:     nullInt32Ptr(f.RetentionDays),
+			RetentionMaxPosts: nullInt32Ptr(f.RetentionMaxPosts),
+		})
+	}
+
+	follows, err := s.db.ListFeedFollows(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get feed follows: %w", err)
+	}
+	for _, f := range follows {
+		a.FeedFollows = append(a.FeedFollows, archiveFollow(f))
+	}
+
+	tags, err := s.db.ListTags(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get tags: %w", err)
+	}
+	for _, t := range tags {
+		a.Tags = append(a.Tags, archiveTag(t))
+	}
+
+	followTags, err := s.db.ListFeedFollowTags(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get feed follow tags: %w", err)
+	}
+	for _, t := range followTags {
+		a.FeedFollowTags = append(a.FeedFollowTags, archiveFollowTag(t))
+	}
+
+	posts, err := s.db.ListPosts(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get posts: %w", err)
+	}
+	for _, p := range posts {
+		a.Posts = append(a.Posts, archivePost{
+			ID:           p.ID,
+			CreatedAt:    p.CreatedAt,
+			UpdatedAt:    p.UpdatedAt,
+			Title:        p.Title,
+			URL:          p.Url,
+			Description:  nullStringPtr(p.Description),
+			PublishedAt:  nullTimePtr(p.PublishedAt),
+			FeedID:       p.FeedID,
+			Author:       nullStringPtr(p.Author),
+			Content:      nullStringPtr(p.Content),
+			Categories:   p.Categories,
+			CanonicalURL: nullStringPtr(p.CanonicalUrl),
+			Fingerprint:  nullStringPtr(p.Fingerprint),
+			ClusterID:    p.ClusterID,
+		})
+	}
+
+	states, err := s.db.ListPostStates(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get post states: %w", err)
+	}
+	for _, ps := range states {
+		a.PostStates = append(a.PostStates, archivePostState{
+			UserID:    ps.UserID,
+			PostID:    ps.PostID,
+			CreatedAt: ps.CreatedAt,
+			UpdatedAt: ps.UpdatedAt,
+			ReadAt:    nullTimePtr(ps.ReadAt),
+			StarredAt: nullTimePtr(ps.StarredAt),
+		})
+	}
+
+	rules, err := s.db.ListPostRules(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get rules: %w", err)
+	}
+	for _, r := range rules {
+		a.PostRules = append(a.PostRules, archivePostRule{
+			ID:        r.ID,
+			CreatedAt: r.CreatedAt,
+			UpdatedAt: r.UpdatedAt,
+			UserID:    r.UserID,
+			FeedID:    nullUUIDPtr(r.FeedID),
+			Field:     r.Field,
+			MatchType: r.MatchType,
+			Pattern:   r.Pattern,
+			Action:    r.Action,
+		})
+	}
+
+	searches, err := s.db.ListSavedSearches(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get saved searches: %w", err)
+	}
+	for _, ss := range searches {
+		var window *int64
+		if ss.WindowSeconds.Valid {
+			window = &ss.WindowSeconds.Int64
+		}
+		a.SavedSearches = append(a.SavedSearches, archiveSavedSearch{
+			ID:            ss.ID,
+			CreatedAt:     ss.CreatedAt,
+			UpdatedAt:     ss.UpdatedAt,
+			UserID:        ss.UserID,
+			Name:          ss.Name,
+			Query:         nullStringPtr(ss.Query),
+			FeedIDs:       ss.FeedIds,
+			Tags:          ss.Tags,
+			Author:        nullStringPtr(ss.Author),
+			WindowSeconds: window,
+		})
+	}
+
+	prefs, err := s.db.ListUserPreferences(ctx)
+	if err != nil {
+		return archive{}, fmt.Errorf("failed to get preferences: %w", err)
+	}
+	for _, p := range prefs {
+		a.Preferences = append(a.Preferences, archivePreference(p))
+	}
+	return a, nil
+}
+
+func writeArchive(w io.Writer, a archive) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(a)
+}
+
+// archiveLine is one line of a JSONL archive. The first line is a header
+// carrying the version; every other line holds one row.
+type archiveLine struct {
+	Type      string          `json:"type"`
+	Version   int             `json:"version,omitempty"`
+	CreatedAt *time.Time      `json:"created_at,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// writeArchiveLines writes a as JSONL, parents before children, so large
+// archives can be processed a line at a time.
+func writeArchiveLines(w io.Writer, a archive) error {
+	enc := json.NewEncoder(w)
+	if err := enc.Encode(archiveLine{Type: "header", Version: a.Version, CreatedAt: &a.CreatedAt}); err != nil {
+		return err
+	}
+	for _, section := range a.sections() {
+		for i := range section.len() {
+			data, err := json.Marshal(section.row(i))
+			if err != nil {
+				return err
+			}
+			if err := enc.Encode(archiveLine{Type: section.typ, Data: data}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// archiveSection gives the JSONL reader and writer access to one table of
+// an archive.
+type archiveSection struct {
+	typ    string
+	len    func() int
+	row    func(i int) any
+	append func(data json.RawMessage) error
+}
+
+func sectionOf[T any](typ string, rows *[]T) archiveSection {
+	return archiveSection{
+		typ: typ,
+		len: func() int { return len(*rows) },
+		row: func(i int) any { return (*rows)[i] },
+		append: func(data json.RawMessage) error {
+			var row T
+			if err := json.Unmarshal(data, &row); err != nil {
+				return err
+			}
+			*rows = append(*rows, row)
+			return nil
+		},
+	}
+}
+
+func (a *archive) sections() []archiveSection {
+	return []archiveSection{
+		sectionOf("user", &a.Users),
+		sectionOf("feed", &a.Feeds),
+		sectionOf("feed_follow", &a.FeedFollows),
+		sectionOf("tag", &a.Tags),
+		sectionOf("feed_follow_tag", &a.FeedFollowTags),
+		sectionOf("post", &a.Posts),
+		sectionOf("post_state", &a.PostStates),
+		sectionOf("post_rule", &a.PostRules),
+		sectionOf("saved_search", &a.SavedSearches),
+		sectionOf("preference", &a.Preferences),
+	}
+}
+
+// readArchive reads a JSON or JSONL archive, telling them apart by
+// whether the first value is a JSONL header.
+func readArchive(r io.Reader) (archive, error) {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	var first json.RawMessage
+	if err := dec.Decode(&first); err != nil {
+		return archive{}, fmt.Errorf("couldn't read archive: %w", err)
+	}
+	var header archiveLine
+	if err := json.Unmarshal(first, &header); err != nil {
+		return archive{}, fmt.Errorf("couldn't read archive: %w", err)
+	}
+
+	var a archive
+	if header.Type != "header" {
+		if err := json.Unmarshal(first, &a); err != nil {
+			return archive{}, fmt.Errorf("couldn't read archive: %w", err)
+		}
+	} else {
+		a.Version = header.Version
+		if header.CreatedAt != nil {
+			a.CreatedAt = *header.CreatedAt
+		}
+		sections := make(map[string]archiveSection)
+		for _, section := range a.sections() {
+			sections[section.typ] = section
+		}
+		for line := 2; ; line++ {
+			var l archiveLine
+			err := dec.Decode(&l)
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return archive{}, fmt.Errorf("couldn't read archive line %d: %w", line, err)
+			}
+			section, ok := sections[l.Type]
+			if !ok {
+				return archive{}, fmt.Errorf("archive line %d: unknown row type %q", line, l.Type)
+			}
+			if err := section.append(l.Data); err != nil {
+				return archive{}, fmt.Errorf("archive line %d: %w", line, err)
+			}
+		}
+	}
+
+	if a.Version < 1 {
+		return archive{}, errors.New("not a gator archive: no version")
+	}
+	if a.Version > archiveVersion {
+		return archive{}, fmt.Errorf("archive version %d is newer than this gator supports (%d)", a.Version, archiveVersion)
+	}
+	return a, nil
+}
+
+// writeArchiveFile snapshots the database to path as JSON or JSONL. The
+// file holds password hashes, so only its owner may read it.
+func writeArchiveFile(s *state, path, format string) error {
+	a, err := buildArchive(s)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	if format == archiveJSONL {
+		err = writeArchiveLines(w, a)
+	} else {
+		err = writeArchive(w, a)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+	if err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// restoreRecord counts, per table, the rows an archive added and the rows
+// that were already there.
+type restoreRecord struct {
+	Table    string `json:"table"`
+	Restored int    `json:"restored"`
+	Existing int    `json:"existing"`
+}
+
+func (r *restoreRecord) count(inserted int64) {
+	if inserted > 0 {
+		r.Restored++
+	} else {
+		r.Existing++
+	}
+}
+
+// restoreArchive inserts a's rows through q, which should be bound to a
+// transaction. Rows matching an existing one by natural key (user name,
+// feed URL, post URL within its feed, ...) are left as they are, and the
+// archive's references to them are pointed at the existing IDs.
+func restoreArchive(q database.Querier, a archive) ([]restoreRecord, error) {
+	ctx := context.Background()
+	ids := make(map[uuid.UUID]uuid.UUID)
+	mapID := func(id uuid.UUID) uuid.UUID {
+		if mapped, ok := ids[id]; ok {
+			return mapped
+		}
+		return id
+	}
+	mapNullID := func(id *uuid.UUID) uuid.NullUUID {
+		if id == nil {
+			return uuid.NullUUID{}
+		}
+		return uuid.NullUUID{UUID: mapID(*id), Valid: true}
+	}
+	records := make([]restoreRecord, 0, 10)
+	track := func(table string) *restoreRecord {
+		records = append(records, restoreRecord{Table: table})
+		return &records[len(records)-1]
+	}
+
+	r := track("users")
+	for _, u := range a.Users {
+		existing, err := q.GetUser(ctx, u.Name)
+		if err == nil {
+			ids[u.ID] = existing.ID
+			r.Existing++
+			continue
+		} else if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("couldn't look up user %s: %w", u.Name, err)
+		}
+		role := u.Role
+		if role == "" {
+			role = roleMember
+		}
+		err = q.RestoreUser(ctx, database.RestoreUserParams{
+			ID:            u.ID,
+			CreatedAt:     u.CreatedAt,
+			UpdatedAt:     u.UpdatedAt,
+			Name:          u.Name,
+			PasswordHash:  ptrNullString(u.PasswordHash),
+			DeactivatedAt: ptrNullTime(u.DeactivatedAt),
+			Role:          role,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore user %s: %w", u.Name, err)
+		}
+		r.Restored++
+	}
+
+	r = track("feeds")
+	for _, f := range a.Feeds {
+		existing, err := q.GetFeedByURL(ctx, f.URL)
+		if err == nil {
+			ids[f.ID] = existing.ID
+			r.Existing++
+			continue
+		} else if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("couldn't look up feed %s: %w", f.URL, err)
+		}
+		err = q.RestoreFeed(ctx, database.RestoreFeedParams{
+			ID:                f.ID,
+			CreatedAt:         f.CreatedAt,
+			UpdatedAt:         f.UpdatedAt,
+			Name:              f.Name,
+			Url:               f.URL,
+			AddedBy:           mapNullID(f.AddedBy),
+			LastFetchedAt:     ptrNullTime(f.LastFetchedAt),
+			RetentionDays:     ptrNullInt32(f.RetentionDays),
+			RetentionMaxPosts: ptrNullInt32(f.RetentionMaxPosts),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore feed %s: %w", f.URL, err)
+		}
+		r.Restored++
+	}
+
+	r = track("feed_follows")
+	for _, f := range a.FeedFollows {
+		userID, feedID := mapID(f.UserID), mapID(f.FeedID)
+		existing, err := q.GetFeedFollowForUser(ctx, database.GetFeedFollowForUserParams{UserID: userID, FeedID: feedID})
+		if err == nil {
+			ids[f.ID] = existing.ID
+			r.Existing++
+			continue
+		} else if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("couldn't look up feed follow: %w", err)
+		}
+		_, err = q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        f.ID,
+			CreatedAt: f.CreatedAt,
+			UpdatedAt: f.UpdatedAt,
+			UserID:    userID,
+			FeedID:    feedID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore feed follow: %w", err)
+		}
+		r.Restored++
+	}
+
+	r = track("tags")
+	for _, t := range a.Tags {
+		userID := mapID(t.UserID)
+		existing, err := q.GetTag(ctx, database.GetTagParams{UserID: userID, Name: t.Name})
+		if err == nil {
+			ids[t.ID] = existing.ID
+			r.Existing++
+			continue
+		} else if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("couldn't look up tag %s: %w", t.Name, err)
+		}
+		_, err = q.UpsertTag(ctx, database.UpsertTagParams{
+			ID:        t.ID,
+			CreatedAt: t.CreatedAt,
+			UpdatedAt: t.UpdatedAt,
+			UserID:    userID,
+			Name:      t.Name,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore tag %s: %w", t.Name, err)
+		}
+		r.Restored++
+	}
+
+	r = track("feed_follow_tags")
+	for _, t := range a.FeedFollowTags {
+		n, err := q.AddFollowTag(ctx, database.AddFollowTagParams{
+			FeedFollowID: mapID(t.FeedFollowID),
+			TagID:        mapID(t.TagID),
+			CreatedAt:    t.CreatedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore feed follow tag: %w", err)
+		}
+		r.count(n)
+	}
+
+	r = track("posts")
+	for _, p := range a.Posts {
+		feedID := mapID(p.FeedID)
+		existing, err := q.GetPostIDByFeedURL(ctx, database.GetPostIDByFeedURLParams{FeedID: feedID, Url: p.URL})
+		if err == nil {
+			ids[p.ID] = existing
+			r.Existing++
+			continue
+		} else if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("couldn't look up post %s: %w", p.URL, err)
+		}
+		_, err = q.CreatePost(ctx, database.CreatePostParams{
+			ID:           p.ID,
+			CreatedAt:    p.CreatedAt,
+			UpdatedAt:    p.UpdatedAt,
+			Title:        p.Title,
+			Url:          p.URL,
+			Description:  ptrNullString(p.Description),
+			PublishedAt:  ptrNullTime(p.PublishedAt),
+			FeedID:       feedID,
+			Author:       ptrNullString(p.Author),
+			Content:      ptrNullString(p.Content),
+			Categories:   orEmpty(p.Categories),
+			CanonicalUrl: ptrNullString(p.CanonicalURL),
+			Fingerprint:  ptrNullString(p.Fingerprint),
+			ClusterID:    mapID(p.ClusterID),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore post %s: %w", p.URL, err)
+		}
+		r.Restored++
+	}
+
+	r = track("post_states")
+	for _, ps := range a.PostStates {
+		n, err := q.RestorePostState(ctx, database.RestorePostStateParams{
+			UserID:    mapID(ps.UserID),
+			PostID:    mapID(ps.PostID),
+			CreatedAt: ps.CreatedAt,
+			UpdatedAt: ps.UpdatedAt,
+			ReadAt:    ptrNullTime(ps.ReadAt),
+			StarredAt: ptrNullTime(ps.StarredAt),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore post state: %w", err)
+		}
+		r.count(n)
+	}
+
+	r = track("post_rules")
+	for _, pr := range a.PostRules {
+		// Archives move between engines, and the regex dialects differ.
+		if pr.MatchType == "regex" {
+			_, err := q.CheckRulePattern(ctx, database.CheckRulePatternParams{
+				MatchType: pr.MatchType,
+				Pattern:   pr.Pattern,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("rule %s has a regular expression this database can't run: %w", pr.ID, err)
+			}
+		}
+		n, err := q.RestorePostRule(ctx, database.RestorePostRuleParams{
+			ID:        pr.ID,
+			CreatedAt: pr.CreatedAt,
+			UpdatedAt: pr.UpdatedAt,
+			UserID:    mapID(pr.UserID),
+			FeedID:    mapNullID(pr.FeedID),
+			Field:     pr.Field,
+			MatchType: pr.MatchType,
+			Pattern:   pr.Pattern,
+			Action:    pr.Action,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore rule: %w", err)
+		}
+		r.count(n)
+	}
+
+	r = track("saved_searches")
+	for _, ss := range a.SavedSearches {
+		userID := mapID(ss.UserID)
+		_, err := q.GetSavedSearch(ctx, database.GetSavedSearchParams{UserID: userID, Name: ss.Name})
+		if err == nil {
+			r.Existing++
+			continue
+		} else if err != sql.ErrNoRows {
+			return nil, fmt.Errorf("couldn't look up saved search %s: %w", ss.Name, err)
+		}
+		feedIDs := make([]uuid.UUID, len(ss.FeedIDs))
+		for i, id := range ss.FeedIDs {
+			feedIDs[i] = mapID(id)
+		}
+		var window sql.NullInt64
+		if ss.WindowSeconds != nil {
+			window = sql.NullInt64{Int64: *ss.WindowSeconds, Valid: true}
+		}
+		_, err = q.CreateSavedSearch(ctx, database.CreateSavedSearchParams{
+			ID:            ss.ID,
+			CreatedAt:     ss.CreatedAt,
+			UpdatedAt:     ss.UpdatedAt,
+			UserID:        userID,
+			Name:          ss.Name,
+			Query:         ptrNullString(ss.Query),
+			FeedIds:       feedIDs,
+			Tags:          orEmpty(ss.Tags),
+			Author:        ptrNullString(ss.Author),
+			WindowSeconds: window,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore saved search %s: %w", ss.Name, err)
+		}
+		r.Restored++
+	}
+
+	r = track("preferences")
+	for _, p := range a.Preferences {
+		n, err := q.RestoreUserPreference(ctx, database.RestoreUserPreferenceParams{
+			UserID:    mapID(p.UserID),
+			Key:       p.Key,
+			Value:     p.Value,
+			UpdatedAt: p.UpdatedAt,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't restore preference %s: %w", p.Key, err)
+		}
+		r.count(n)
+	}
+	return records, nil
+}
+
+func nullStringPtr(s sql.NullString) *string {
+	if !s.Valid {
+		return nil
+	}
+	return &s.String
+}
+
+func nullUUIDPtr(id uuid.NullUUID) *uuid.UUID {
+	if !id.Valid {
+		return nil
+	}
+	return &id.UUID
+}
+
+func nullInt32Ptr(n sql.NullInt32) *int32 {
+	if !n.Valid {
+		return nil
+	}
+	return &n.Int32
+}
+
+func ptrNullString(s *string) sql.NullString {
+	if s == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: *s, Valid: true}
+}
+
+func ptrNullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+func ptrNullInt32(n *int32) sql.NullInt32 {
+	if n == nil {
+		return sql.NullInt32{}
+	}
+	return sql.NullInt32{Int32: *n, Valid: true}
+}
+
+// orEmpty turns a missing list into an empty one, since the array columns
+// are NOT NULL.
+func orEmpty(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
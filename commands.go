@@ -1,15 +1,30 @@
 package main
 
 import (
+	"bufio"
+	"database/sql"
+	"flag"
 	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
 
 	"github.com/Grumpster-Dev/gator/internal/config"
-	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/migrate"
+	"github.com/Grumpster-Dev/gator/internal/storage"
 )
 
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db storage.Store
+	// engine is the database behind db, for the few things that differ.
+	engine   storage.Engine
+	migrator *migrate.Migrator
+	cfg      *config.Config
+	output   outputOptions
+	// prefs holds the logged-in user's preferences; see s.pref.
+	prefs map[prefKey]string
 }
 
 type command struct {
@@ -31,3 +46,169 @@ func (c *commands) run(s *state, cmd command) error {
 func (c *commands) register(name string, f func(*state, command) error) {
 	c.registeredCommands[name] = f
 }
+
+// stdin is shared by every prompt so that buffered input isn't lost
+// between them when answers are piped in.
+var stdin = bufio.NewReader(os.Stdin)
+
+// confirm asks a yes/no question on stderr and reports whether the answer
+// was yes.
+func confirm(prompt string) (bool, error) {
+	fmt.Fprintf(os.Stderr, "%s [y/N] ", prompt)
+	answer, err := stdin.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, fmt.Errorf("couldn't read answer: %w", err)
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes", nil
+}
+
+// queryCommands take a search query as their positional arguments. Once
+// the query has started, single-dash words in it such as -docker are
+// query terms rather than flags; see parseQueryFlags.
+var queryCommands = []string{"search"}
+
+// globalFlags are the flags main takes out of the arguments before running
+// a command. They all take a value.
+var globalFlags = []string{"config", "profile", "output", "o", "template"}
+
+// extractGlobalFlags removes the named flags (in either `--flag value` or
+// `--flag=value` form) from anywhere in args, stopping at "--", and returns
+// their values along with the remaining arguments. Inside the query of a
+// query command only the double-dash form counts.
+func extractGlobalFlags(args []string, names ...string) (map[string]string, []string, error) {
+	values := make(map[string]string)
+	var rest []string
+	var command string
+	inQuery := false
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			rest = append(rest, args[i:]...)
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			if command == "" {
+				command = arg
+			} else if slices.Contains(queryCommands, command) {
+				inQuery = true
+			}
+			rest = append(rest, arg)
+			continue
+		}
+		name, value, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if inQuery && !strings.HasPrefix(arg, "--") {
+			rest = append(rest, arg)
+			continue
+		}
+		if !slices.Contains(names, name) {
+			rest = append(rest, arg)
+			// Keep another global flag's value with it, so that it isn't
+			// taken for the command.
+			if slices.Contains(globalFlags, name) && !hasValue && i+1 < len(args) {
+				i++
+				rest = append(rest, args[i])
+			}
+			continue
+		}
+		if !hasValue {
+			if i+1 >= len(args) {
+				return nil, nil, fmt.Errorf("flag needs an argument: %s", arg)
+			}
+			i++
+			value = args[i]
+		}
+		values[name] = value
+	}
+	return values, rest, nil
+}
+
+// parseFlags parses args against fs, allowing flags and positional
+// arguments to be mixed (e.g. `browse 10 --sort published`), and returns
+// the positional arguments in order.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// parseQueryFlags is parseFlags for query commands. After the first word
+// of the query, single-dash words belong to it, so that
+// `search kubernetes -docker` excludes docker; flags there need two dashes.
+func parseQueryFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var query []string
+	for len(args) > 0 {
+		arg := args[0]
+		switch {
+		case arg == "--":
+			return append(query, args[1:]...), nil
+		case !strings.HasPrefix(arg, "-") || arg == "-",
+			len(query) > 0 && !strings.HasPrefix(arg, "--"):
+			query = append(query, arg)
+			args = args[1:]
+			continue
+		}
+		// Parse this flag alone, with its value if it takes one.
+		n := 1
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if f := fs.Lookup(name); f != nil && !hasValue && !isBoolFlag(f) && len(args) > 1 {
+			n = 2
+		}
+		if err := fs.Parse(args[:n]); err != nil {
+			return nil, err
+		}
+		args = args[n:]
+	}
+	return query, nil
+}
+
+func isBoolFlag(f *flag.Flag) bool {
+	b, ok := f.Value.(interface{ IsBoolFlag() bool })
+	return ok && b.IsBoolFlag()
+}
+
+// stringList is a flag.Value for flags that may be repeated.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+// parseTimeArg accepts either a duration relative to now (e.g. "24h") or an
+// absolute date ("2006-01-02" or RFC 3339).
+func parseTimeArg(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().UTC().Add(-d), nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q: use a duration like 24h, a date like 2006-01-02 or RFC 3339", value)
+}
+
+// nullTimePtr converts a nullable column into a pointer so that records
+// print missing times as null rather than the zero time.
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestExtractGlobalFlags(t *testing.T) {
+	tests := []struct {
+		args     []string
+		names    []string
+		want     map[string]string
+		wantRest []string
+	}{
+		{
+			args:     []string{"--profile", "work", "browse", "5"},
+			names:    []string{"config", "profile"},
+			want:     map[string]string{"profile": "work"},
+			wantRest: []string{"browse", "5"},
+		},
+		{
+			args:     []string{"browse", "-o", "json", "--config=/tmp/c.json"},
+			names:    []string{"config", "profile"},
+			want:     map[string]string{"config": "/tmp/c.json"},
+			wantRest: []string{"browse", "-o", "json"},
+		},
+		{
+			args:     []string{"browse", "-o", "json"},
+			names:    []string{"output", "o", "template"},
+			want:     map[string]string{"o": "json"},
+			wantRest: []string{"browse"},
+		},
+		{
+			args:     []string{"search", "-o", "csv", "kubernetes", "-o", "-template", "--output", "json"},
+			names:    []string{"output", "o", "template"},
+			want:     map[string]string{"o": "csv", "output": "json"},
+			wantRest: []string{"search", "kubernetes", "-o", "-template"},
+		},
+		{
+			// The value of --output isn't the command, so -profile still
+			// counts in front of the query.
+			args:     []string{"--output", "json", "search", "-profile", "work", "gophers", "-profile"},
+			names:    []string{"config", "profile"},
+			want:     map[string]string{"profile": "work"},
+			wantRest: []string{"--output", "json", "search", "gophers", "-profile"},
+		},
+		{
+			args:     []string{"browse", "--", "--profile", "x"},
+			names:    []string{"profile"},
+			want:     map[string]string{},
+			wantRest: []string{"browse", "--", "--profile", "x"},
+		},
+	}
+	for _, tt := range tests {
+		got, rest, err := extractGlobalFlags(tt.args, tt.names...)
+		if err != nil {
+			t.Errorf("extractGlobalFlags(%q): %v", tt.args, err)
+			continue
+		}
+		if !maps.Equal(got, tt.want) || !slices.Equal(rest, tt.wantRest) {
+			t.Errorf("extractGlobalFlags(%q) = %v, %q; want %v, %q", tt.args, got, rest, tt.want, tt.wantRest)
+		}
+	}
+}
+
+func TestParseQueryFlags(t *testing.T) {
+	tests := []struct {
+		args      []string
+		want      []string
+		wantLimit int
+		wantFeed  string
+	}{
+		{
+			args:      []string{"kubernetes", "-docker"},
+			want:      []string{"kubernetes", "-docker"},
+			wantLimit: 10,
+		},
+		{
+			args:      []string{"--limit", "5", "-feed", "news", "go", "-generics", "OR", "rust"},
+			want:      []string{"go", "-generics", "OR", "rust"},
+			wantLimit: 5,
+			wantFeed:  "news",
+		},
+		{
+			// Defined flags need two dashes once the query has started.
+			args:      []string{"go", "-limit", "--limit=3", "--feed", "blog"},
+			want:      []string{"go", "-limit"},
+			wantLimit: 3,
+			wantFeed:  "blog",
+		},
+		{
+			args:      []string{"-limit", "2", "--", "-o", "--limit"},
+			want:      []string{"-o", "--limit"},
+			wantLimit: 2,
+		},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("search", flag.ContinueOnError)
+		limit := fs.Int("limit", 10, "")
+		feed := fs.String("feed", "", "")
+		got, err := parseQueryFlags(fs, tt.args)
+		if err != nil {
+			t.Errorf("parseQueryFlags(%q): %v", tt.args, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) || *limit != tt.wantLimit || *feed != tt.wantFeed {
+			t.Errorf("parseQueryFlags(%q) = %q, limit %d, feed %q; want %q, %d, %q",
+				tt.args, got, *limit, *feed, tt.want, tt.wantLimit, tt.wantFeed)
+		}
+	}
+}
+
+func TestParseQueryFlagsErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-docker", "kubernetes"},
+		{"go", "--unknown"},
+		{"go", "--limit"},
+	} {
+		fs := flag.NewFlagSet("search", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.Int("limit", 10, "")
+		if _, err := parseQueryFlags(fs, args); err == nil {
+			t.Errorf("parseQueryFlags(%q) succeeded, want an error", args)
+		}
+	}
+}
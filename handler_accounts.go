@@ -0,0 +1,186 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+)
+
+// checkCanManage allows users to manage their own account and admins to
+// manage anyone's.
+func checkCanManage(actor, target database.User) error {
+	if actor.ID != target.ID && !hasPermission(actor, permAdmin) {
+		return fmt.Errorf("only admins can manage other users' accounts")
+	}
+	return nil
+}
+
+// checkNotLastAdmin refuses to remove or deactivate the only active admin
+// while other users still depend on one.
+func checkNotLastAdmin(s *state, target database.User) error {
+	if target.Role != roleAdmin || target.DeactivatedAt.Valid {
+		return nil
+	}
+	admins, err := s.db.CountActiveAdmins(context.Background())
+	if err != nil {
+		return fmt.Errorf("couldn't count admins: %w", err)
+	}
+	users, err := s.db.CountUsers(context.Background())
+	if err != nil {
+		return fmt.Errorf("couldn't count users: %w", err)
+	}
+	if admins <= 1 && users > 1 {
+		return fmt.Errorf("%s is the last admin; make another user an admin first", target.Name)
+	}
+	return nil
+}
+
+// deleteUser removes target. Feeds they added stay for their other
+// followers; the ones left without followers are collected afterwards.
+func deleteUser(s *state, actor, target database.User) error {
+	if err := checkNotLastAdmin(s, target); err != nil {
+		return err
+	}
+	if _, err := s.db.DeleteUser(context.Background(), target.ID); err != nil {
+		return fmt.Errorf("couldn't delete user %s: %w", target.Name, err)
+	}
+	fmt.Printf("Deleted user %s.\n", target.Name)
+	if err := collectUnusedFeeds(s); err != nil {
+		return err
+	}
+	if target.ID == actor.ID {
+		if err := s.cfg.ClearSession(); err != nil {
+			return fmt.Errorf("failed to update config: %w", err)
+		}
+		fmt.Println("You have been logged out.")
+	}
+	if err := s.cfg.ForgetUser(target.Name); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+	return nil
+}
+
+func handlerDeleteUser(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s <name> [--yes]", cmd.Name)
+	}
+	target, err := s.db.GetUser(context.Background(), args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find user %s: %w", args[0], err)
+	}
+	if err := checkCanManage(user, target); err != nil {
+		return err
+	}
+	if !*yes {
+		ok, err := confirm(fmt.Sprintf("Delete user %s with their follows, tags, rules and read state?", target.Name))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("cancelled")
+		}
+	}
+	return deleteUser(s, user, target)
+}
+
+func handlerRenameUser(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <name> <new_name>", cmd.Name)
+	}
+	target, err := s.db.GetUser(context.Background(), cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find user %s: %w", cmd.Args[0], err)
+	}
+	if err := checkCanManage(user, target); err != nil {
+		return err
+	}
+	newName := cmd.Args[1]
+	_, err = s.db.GetUser(context.Background(), newName)
+	if err == nil {
+		return fmt.Errorf("user %s already exists", newName)
+	}
+	if err != sql.ErrNoRows {
+		return fmt.Errorf("couldn't check existing users: %w", err)
+	}
+
+	err = s.db.RenameUser(context.Background(), database.RenameUserParams{ID: target.ID, Name: newName})
+	if err != nil {
+		return fmt.Errorf("couldn't rename user: %w", err)
+	}
+	if err := s.cfg.RenameSession(target.Name, newName); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+	fmt.Printf("Renamed user %s to %s.\n", target.Name, newName)
+	return nil
+}
+
+// handlerDeactivate blocks a user from logging in without deleting their
+// data, and ends their sessions.
+func handlerDeactivate(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	undo := fs.Bool("undo", false, "reactivate the user")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s <name> [--undo]", cmd.Name)
+	}
+	ctx := context.Background()
+	target, err := s.db.GetUser(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find user %s: %w", args[0], err)
+	}
+
+	if *undo {
+		// Deactivated users can't log in, so only an admin can undo it.
+		if err := checkPermission(user, permAdmin); err != nil {
+			return err
+		}
+		err := s.db.SetUserDeactivated(ctx, database.SetUserDeactivatedParams{ID: target.ID})
+		if err != nil {
+			return fmt.Errorf("couldn't reactivate user: %w", err)
+		}
+		fmt.Printf("Reactivated user %s.\n", target.Name)
+		return nil
+	}
+
+	if err := checkCanManage(user, target); err != nil {
+		return err
+	}
+	if target.DeactivatedAt.Valid {
+		return fmt.Errorf("user %s is already deactivated", target.Name)
+	}
+	if err := checkNotLastAdmin(s, target); err != nil {
+		return err
+	}
+	err = s.db.SetUserDeactivated(ctx, database.SetUserDeactivatedParams{
+		ID:            target.ID,
+		DeactivatedAt: sql.NullTime{Time: time.Now().UTC(), Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't deactivate user: %w", err)
+	}
+	if err := s.db.DeleteSessionsForUser(ctx, target.ID); err != nil {
+		return fmt.Errorf("couldn't end sessions: %w", err)
+	}
+	fmt.Printf("Deactivated user %s.\n", target.Name)
+	if target.ID == user.ID {
+		if err := s.cfg.ClearSession(); err != nil {
+			return fmt.Errorf("failed to update config: %w", err)
+		}
+		fmt.Println("You have been logged out.")
+	}
+	return nil
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/config"
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/storage"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/term"
+)
+
+const (
+	minPasswordLength = 8
+	sessionLifetime   = 30 * 24 * time.Hour
+	resetLifetime     = 24 * time.Hour
+)
+
+// readPassword prompts on stderr and reads a line from stdin, without
+// echo when stdin is a terminal.
+func readPassword(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		password, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("couldn't read password: %w", err)
+		}
+		return string(password), nil
+	}
+	line, err := stdin.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", fmt.Errorf("couldn't read password: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// promptNewPassword asks for a new password twice and returns its hash.
+func promptNewPassword() (string, error) {
+	password, err := readPassword("New password: ")
+	if err != nil {
+		return "", err
+	}
+	if len(password) < minPasswordLength {
+		return "", fmt.Errorf("password must be at least %d characters", minPasswordLength)
+	}
+	confirm, err := readPassword("Repeat password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != confirm {
+		return "", errors.New("passwords don't match")
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", fmt.Errorf("couldn't hash password: %w", err)
+	}
+	return string(hash), nil
+}
+
+func checkPassword(hash, password string) error {
+	if err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)); err != nil {
+		return errors.New("incorrect password")
+	}
+	return nil
+}
+
+// newToken returns a random token for a session or a password reset.
+// Only its hash (see hashToken) goes in the database.
+func newToken() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("couldn't generate token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// startSession creates a session for user and saves its token in the
+// config.
+func startSession(s *state, user database.User) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	err = s.db.CreateSession(context.Background(), database.CreateSessionParams{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		CreatedAt: now,
+		ExpiresAt: now.Add(sessionLifetime),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't create session: %w", err)
+	}
+	if err := s.cfg.SetSession(user.Name, token); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+	return nil
+}
+
+// currentUser returns the user owning the session in the config.
+func currentUser(s *state) (database.User, error) {
+	want := s.cfg.User()
+	if s.cfg.SessionToken == "" {
+		if want != "" {
+			return database.User{}, fmt.Errorf("not logged in as %s (from %s): run login %s", want, config.EnvUser, want)
+		}
+		return database.User{}, errors.New("not logged in: run login <name>")
+	}
+	user, err := s.db.GetUserBySession(context.Background(), hashToken(s.cfg.SessionToken))
+	if err == sql.ErrNoRows {
+		return database.User{}, errors.New("session expired or invalid: run login <name>")
+	}
+	if err != nil {
+		return database.User{}, fmt.Errorf("couldn't find current user: %w", err)
+	}
+	if want != "" && user.Name != want {
+		return database.User{}, fmt.Errorf("the session is for %s, not %s (from %s): run login %s", user.Name, want, config.EnvUser, want)
+	}
+	return user, nil
+}
+
+func handlerLogout(s *state, cmd command) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	if s.cfg.SessionToken == "" {
+		return errors.New("not logged in")
+	}
+	if err := s.db.DeleteSession(context.Background(), hashToken(s.cfg.SessionToken)); err != nil {
+		return fmt.Errorf("couldn't end session: %w", err)
+	}
+	if err := s.cfg.ClearSession(); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+	fmt.Println("Logged out.")
+	return nil
+}
+
+// handlerPasswd changes the current user's password and signs out every
+// other session. Admins can name another user to reset their password.
+func handlerPasswd(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %s [name]", cmd.Name)
+	}
+	if len(cmd.Args) == 1 && cmd.Args[0] != user.Name {
+		if err := checkPermission(user, permAdmin); err != nil {
+			return err
+		}
+		target, err := s.db.GetUser(context.Background(), cmd.Args[0])
+		if err != nil {
+			return fmt.Errorf("couldn't find user %s: %w", cmd.Args[0], err)
+		}
+		return resetPassword(s, target)
+	}
+	if user.PasswordHash.Valid {
+		password, err := readPassword("Current password: ")
+		if err != nil {
+			return err
+		}
+		if err := checkPassword(user.PasswordHash.String, password); err != nil {
+			return err
+		}
+	}
+	hash, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+
+	err = s.db.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+		ID:           user.ID,
+		PasswordHash: sql.NullString{String: hash, Valid: true},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set password: %w", err)
+	}
+	if err := s.db.DeleteSessionsForUser(context.Background(), user.ID); err != nil {
+		return fmt.Errorf("couldn't end other sessions: %w", err)
+	}
+	if err := startSession(s, user); err != nil {
+		return err
+	}
+	fmt.Println("Password changed.")
+	return nil
+}
+
+// resetPassword clears target's password, signs them out everywhere and
+// prints a one-time token that lets them choose a new one at login. It is
+// the only way an account without a password gets one.
+func resetPassword(s *state, target database.User) error {
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	err = s.db.InTx(context.Background(), func(tx storage.Store) error {
+		err := tx.SetUserPassword(context.Background(), database.SetUserPasswordParams{ID: target.ID})
+		if err != nil {
+			return fmt.Errorf("couldn't clear password: %w", err)
+		}
+		if err := tx.DeleteSessionsForUser(context.Background(), target.ID); err != nil {
+			return fmt.Errorf("couldn't end sessions: %w", err)
+		}
+		err = tx.UpsertPasswordReset(context.Background(), database.UpsertPasswordResetParams{
+			UserID:    target.ID,
+			TokenHash: hashToken(token),
+			CreatedAt: now,
+			ExpiresAt: now.Add(resetLifetime),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't create reset token: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Password for %s reset. Give them this one-time token, valid for %d hours, to enter at `gator login %s`:\n%s\n",
+		target.Name, int(resetLifetime.Hours()), target.Name, token)
+	return nil
+}
+
+// redeemPasswordReset lets a user without a password log in with a reset
+// token from an admin, and has them choose a password.
+func redeemPasswordReset(s *state, user database.User) error {
+	pending, err := s.db.HasPasswordReset(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't check for a password reset: %w", err)
+	}
+	if !pending {
+		return fmt.Errorf("user %s has no password; an admin has to run `gator passwd %s` to reset it", user.Name, user.Name)
+	}
+	token, err := readPassword("Reset token: ")
+	if err != nil {
+		return err
+	}
+	hash, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+	return s.db.InTx(context.Background(), func(tx storage.Store) error {
+		n, err := tx.UsePasswordReset(context.Background(), database.UsePasswordResetParams{
+			UserID:    user.ID,
+			TokenHash: hashToken(strings.TrimSpace(token)),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't check reset token: %w", err)
+		}
+		if n == 0 {
+			return errors.New("invalid or expired reset token")
+		}
+		err = tx.SetUserPassword(context.Background(), database.SetUserPasswordParams{
+			ID:           user.ID,
+			PasswordHash: sql.NullString{String: hash, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't set password: %w", err)
+		}
+		return nil
+	})
+}
+
+// issueAdminResets bootstraps a database from before passwords existed,
+// where no one can log in to reset anyone: each active admin without a
+// password gets a reset token. It does nothing once anyone has a password.
+func issueAdminResets(s *state) error {
+	ctx := context.Background()
+	withPassword, err := s.db.CountUsersWithPassword(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't count users with passwords: %w", err)
+	}
+	if withPassword > 0 {
+		return nil
+	}
+	users, err := s.db.GetUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get users: %w", err)
+	}
+	for _, user := range users {
+		if user.Role != roleAdmin || user.DeactivatedAt.Valid {
+			continue
+		}
+		if err := resetPassword(s, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
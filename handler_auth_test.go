@@ -0,0 +1,164 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/config"
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func createTestUser(t *testing.T, s *state, name, role string, passwordHash sql.NullString) database.User {
+	t.Helper()
+	user, err := s.db.CreateUser(context.Background(), database.CreateUserParams{
+		ID:           uuid.New(),
+		CreatedAt:    time.Now().UTC(),
+		UpdatedAt:    time.Now().UTC(),
+		Name:         name,
+		PasswordHash: passwordHash,
+		Role:         role,
+	})
+	if err != nil {
+		t.Fatalf("creating user %s: %v", name, err)
+	}
+	return user
+}
+
+func TestLoginWithoutPasswordNeedsReset(t *testing.T) {
+	s := newTestState(t)
+	user := createTestUser(t, s, "legacy", roleAdmin, sql.NullString{})
+
+	withStdin(t, "password1\npassword1\n")
+	err := handlerLogin(s, command{Name: "login", Args: []string{"legacy"}})
+	if err == nil || !strings.Contains(err.Error(), "has no password") {
+		t.Fatalf("login without a password = %v, want a refusal", err)
+	}
+	if s.cfg.SessionToken != "" {
+		t.Fatal("a refused login started a session")
+	}
+	got, err := s.db.GetUser(context.Background(), user.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.PasswordHash.Valid {
+		t.Fatal("a refused login set a password")
+	}
+}
+
+func TestPasswordReset(t *testing.T) {
+	s := newTestState(t)
+	admin := createTestUser(t, s, "admin", roleAdmin, sql.NullString{})
+	member := createTestUser(t, s, "member", roleMember, sql.NullString{})
+
+	if err := handlerPasswd(s, command{Name: "passwd", Args: []string{"admin"}}, member); err == nil {
+		t.Fatal("a member reset an admin's password")
+	}
+
+	if err := issueAdminResets(s); err != nil {
+		t.Fatalf("issueAdminResets: %v", err)
+	}
+	pending, err := s.db.HasPasswordReset(context.Background(), admin.ID)
+	if err != nil || !pending {
+		t.Fatalf("admin has no pending reset after issueAdminResets (%v)", err)
+	}
+	if pending, _ := s.db.HasPasswordReset(context.Background(), member.ID); pending {
+		t.Fatal("issueAdminResets gave a member a reset token")
+	}
+
+	// Reissue with a known token to log in with.
+	token := "known-token"
+	err = s.db.UpsertPasswordReset(context.Background(), database.UpsertPasswordResetParams{
+		UserID:    admin.ID,
+		TokenHash: hashToken(token),
+		CreatedAt: time.Now().UTC(),
+		ExpiresAt: time.Now().UTC().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withStdin(t, "wrong-token\npassword1\npassword1\n")
+	if err := handlerLogin(s, command{Name: "login", Args: []string{"admin"}}); err == nil {
+		t.Fatal("login with a wrong reset token succeeded")
+	}
+
+	withStdin(t, token+"\npassword1\npassword1\n")
+	if err := handlerLogin(s, command{Name: "login", Args: []string{"admin"}}); err != nil {
+		t.Fatalf("login with the reset token: %v", err)
+	}
+	if s.cfg.SessionToken == "" {
+		t.Fatal("login with the reset token didn't start a session")
+	}
+
+	// The token is spent and the new password works.
+	if pending, _ := s.db.HasPasswordReset(context.Background(), admin.ID); pending {
+		t.Fatal("the reset token is still pending after use")
+	}
+	withStdin(t, "password1\n")
+	if err := handlerLogin(s, command{Name: "login", Args: []string{"admin"}}); err != nil {
+		t.Fatalf("login with the new password: %v", err)
+	}
+
+	// Once someone has a password, migrate up hands out no more tokens.
+	if err := issueAdminResets(s); err != nil {
+		t.Fatal(err)
+	}
+	if pending, _ := s.db.HasPasswordReset(context.Background(), admin.ID); pending {
+		t.Fatal("issueAdminResets issued a token although an admin has a password")
+	}
+}
+
+func TestExpiredPasswordReset(t *testing.T) {
+	s := newTestState(t)
+	user := createTestUser(t, s, "late", roleMember, sql.NullString{})
+	err := s.db.UpsertPasswordReset(context.Background(), database.UpsertPasswordResetParams{
+		UserID:    user.ID,
+		TokenHash: hashToken("old-token"),
+		CreatedAt: time.Now().UTC().Add(-2 * resetLifetime),
+		ExpiresAt: time.Now().UTC().Add(-resetLifetime),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	withStdin(t, "old-token\npassword1\npassword1\n")
+	if err := handlerLogin(s, command{Name: "login", Args: []string{"late"}}); err == nil {
+		t.Fatal("login with an expired reset token succeeded")
+	}
+}
+
+func TestCurrentUserOverride(t *testing.T) {
+	s := newTestState(t)
+	hash := sql.NullString{String: "unused", Valid: true}
+	alice := createTestUser(t, s, "alice", roleAdmin, hash)
+	bob := createTestUser(t, s, "bob", roleMember, hash)
+	for _, user := range []database.User{bob, alice} {
+		if err := startSession(s, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	current := func(env string) (database.User, error) {
+		t.Helper()
+		t.Setenv(config.EnvUser, env)
+		cfg, err := config.Read(config.Options{Path: s.cfg.Path()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		s.cfg = &cfg
+		return currentUser(s)
+	}
+
+	if user, err := current(""); err != nil || user.ID != alice.ID {
+		t.Errorf("without GATOR_USER: %s, %v; want alice", user.Name, err)
+	}
+	if user, err := current("bob"); err != nil || user.ID != bob.ID {
+		t.Errorf("GATOR_USER=bob: %s, %v; want bob", user.Name, err)
+	}
+	if _, err := current("carol"); err == nil {
+		t.Error("GATOR_USER=carol, who never logged in, succeeded")
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/config"
+	"github.com/Grumpster-Dev/gator/internal/storage"
+)
+
+type configRecord struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+// handlerConfig reads and edits the current profile of the config file.
+func handlerConfig(s *state, cmd command) error {
+	usage := fmt.Errorf("usage: %s get <key> [--show-secrets] | %s set <key> <value> [--no-check] | %s unset <key> | %s list [--show-secrets] | %s path", cmd.Name, cmd.Name, cmd.Name, cmd.Name, cmd.Name)
+	if len(cmd.Args) == 0 {
+		return usage
+	}
+	sub := command{Name: cmd.Name + " " + cmd.Args[0], Args: cmd.Args[1:]}
+	switch cmd.Args[0] {
+	case "get":
+		return handlerConfigGet(s, sub)
+	case "set":
+		return handlerConfigSet(s, sub)
+	case "unset":
+		return handlerConfigUnset(s, sub)
+	case "list":
+		return handlerConfigList(s, sub)
+	case "path":
+		if len(sub.Args) != 0 {
+			return fmt.Errorf("usage: %s", sub.Name)
+		}
+		fmt.Println(s.cfg.Path())
+		return nil
+	}
+	return usage
+}
+
+func handlerConfigGet(s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	showSecrets := fs.Bool("show-secrets", false, "print passwords and tokens instead of redacting them")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s <key> [--show-secrets]", cmd.Name)
+	}
+	value, err := s.cfg.Get(args[0], *showSecrets)
+	if err != nil {
+		return err
+	}
+	fmt.Println(value)
+	return nil
+}
+
+func handlerConfigSet(s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	noCheck := fs.Bool("no-check", false, "save db_url without checking that the database is reachable")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: %s <key> <value> [--no-check]", cmd.Name)
+	}
+	key, value := args[0], args[1]
+	if err := config.Validate(key, value); err != nil {
+		return err
+	}
+	if key == "db_url" && !*noCheck {
+		if err := checkDBURL(value); err != nil {
+			return fmt.Errorf("couldn't connect to %s: %w (use --no-check to save it anyway)", config.RedactDBURL(value), err)
+		}
+	}
+	if err := s.cfg.Set(key, value); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+	fmt.Printf("Set %s in profile %s.\n", key, s.cfg.Profile())
+	return nil
+}
+
+func handlerConfigUnset(s *state, cmd command) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <key>", cmd.Name)
+	}
+	if err := s.cfg.Unset(cmd.Args[0]); err != nil {
+		return fmt.Errorf("failed to update config: %w", err)
+	}
+	fmt.Printf("Unset %s in profile %s.\n", cmd.Args[0], s.cfg.Profile())
+	return nil
+}
+
+func handlerConfigList(s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	showSecrets := fs.Bool("show-secrets", false, "print passwords and tokens instead of redacting them")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: %s [--show-secrets]", cmd.Name)
+	}
+	var records []configRecord
+	for _, key := range config.Keys() {
+		value, err := s.cfg.Get(key, *showSecrets)
+		if err != nil {
+			return err
+		}
+		records = append(records, configRecord{Key: key, Value: value})
+	}
+	return s.printRecords(records)
+}
+
+// checkDBURL pings the database at dbURL. A SQLite file only has to exist,
+// since opening a missing one would create it.
+func checkDBURL(dbURL string) error {
+	if path, ok := storage.SQLitePath(dbURL); ok {
+		_, err := os.Stat(path)
+		return err
+	}
+	db, err := storage.Open(dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return db.Ping(ctx)
+}
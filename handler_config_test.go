@@ -0,0 +1,27 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestConfigSetChecksSQLiteFileExists(t *testing.T) {
+	s := newTestState(t)
+	path := filepath.Join(t.TempDir(), "missing.db")
+	cmd := command{Name: "config", Args: []string{"db_url", "sqlite:" + path}}
+	if err := handlerConfigSet(s, cmd); err == nil {
+		t.Fatal("setting db_url to a missing SQLite file succeeded")
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("checking db_url created %s (stat: %v)", path, err)
+	}
+
+	if err := os.WriteFile(path, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() error { return handlerConfigSet(s, cmd) })
+	if s.cfg.DBURL != "sqlite:"+path {
+		t.Fatalf("db_url = %q after setting it to an existing file", s.cfg.DBURL)
+	}
+}
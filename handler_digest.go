@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"mime"
+	"net"
+	"net/smtp"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/htmltext"
+	"github.com/google/uuid"
+)
+
+const digestSummaryLength = 280
+
+type digest struct {
+	User   string
+	Since  time.Time
+	Until  time.Time
+	Total  int
+	Groups []digestGroup
+}
+
+type digestGroup struct {
+	Name  string
+	Posts []digestPost
+}
+
+type digestPost struct {
+	Title       string
+	URL         string
+	FeedName    string
+	Summary     string
+	PublishedAt time.Time
+}
+
+var digestTextTemplate = template.Must(template.New("text").Parse(
+	`Digest for {{.User}}: {{.Total}} new posts since {{.Since.Format "2006-01-02 15:04"}}
+{{range .Groups}}
+== {{.Name}} ==
+{{range .Posts}}
+* {{.Title}}
+  {{.URL}}
+{{- if .Summary}}
+  {{.Summary}}
+{{- end}}
+{{end}}{{end}}`))
+
+var digestMarkdownTemplate = template.Must(template.New("markdown").Funcs(template.FuncMap{
+	"md":    markdownEscape,
+	"mdURL": markdownURL,
+}).Parse(
+	`# Digest for {{md .User}}
+
+{{.Total}} new posts since {{.Since.Format "2006-01-02 15:04"}}.
+{{range .Groups}}
+## {{md .Name}}
+{{range .Posts}}
+- [{{md .Title}}]({{mdURL .URL}}){{if .Summary}}  
+  {{md .Summary}}{{end}}
+{{- end}}
+{{end}}`))
+
+// markdownEscaper backslash-escapes the characters that would turn feed
+// text into markup or break the digest's links.
+var markdownEscaper = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", `*`, `\*`, `_`, `\_`, `[`, `\[`, `]`, `\]`,
+	`<`, `\<`, `>`, `\>`, `#`, `\#`, `|`, `\|`, `~`, `\~`,
+)
+
+func markdownEscape(text string) string {
+	return markdownEscaper.Replace(text)
+}
+
+// markdownURLEscaper percent-encodes the characters that end a markdown
+// link target early.
+var markdownURLEscaper = strings.NewReplacer(" ", "%20", "(", "%28", ")", "%29", "<", "%3C", ">", "%3E")
+
+func markdownURL(u string) string {
+	return markdownURLEscaper.Replace(u)
+}
+
+var digestHTMLTemplate = htmltemplate.Must(htmltemplate.New("html").Parse(
+	`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Digest for {{.User}}</title></head>
+<body>
+<h1>Digest for {{.User}}</h1>
+<p>{{.Total}} new posts since {{.Since.Format "2006-01-02 15:04"}}.</p>
+{{range .Groups}}<h2>{{.Name}}</h2>
+<ul>
+{{range .Posts}}<li><a href="{{.URL}}">{{.Title}}</a>{{if .Summary}}<br>{{.Summary}}{{end}}</li>
+{{end}}</ul>
+{{end}}</body>
+</html>
+`))
+
+// handlerDigest collects the posts fetched for the user's follows in the
+// last --since window and renders them grouped by feed or tag, either to
+// stdout, a file, or by email through the configured SMTP server.
+func handlerDigest(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	since := fs.String("since", "24h", "include posts fetched after this duration (24h) or date")
+	format := fs.String("format", "text", "digest format: text, markdown or html")
+	groupBy := fs.String("group", "feed", "group posts by feed or tag")
+	outPath := fs.String("out", "", "write the digest to this file instead of stdout")
+	send := fs.Bool("send", false, "email the digest using the smtp settings in the config")
+	limit := fs.Int("limit", 500, "maximum number of posts to include")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: %s [--since 24h] [--format text|markdown|html] [--group feed|tag] [--out file] [--send]", cmd.Name)
+	}
+	if *format != "text" && *format != "markdown" && *format != "html" {
+		return fmt.Errorf("invalid format %q: use text, markdown or html", *format)
+	}
+	if *groupBy != "feed" && *groupBy != "tag" {
+		return fmt.Errorf("invalid grouping %q: use feed or tag", *groupBy)
+	}
+	if *limit < 1 {
+		return fmt.Errorf("post limit must be positive")
+	}
+	sinceTime, err := parseTimeArg(*since)
+	if err != nil {
+		return err
+	}
+
+	d, err := buildDigest(s, user, sinceTime, *groupBy, *limit)
+	if err != nil {
+		return err
+	}
+	var body bytes.Buffer
+	switch *format {
+	case "html":
+		err = digestHTMLTemplate.Execute(&body, d)
+	case "markdown":
+		err = digestMarkdownTemplate.Execute(&body, d)
+	default:
+		err = digestTextTemplate.Execute(&body, d)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't render digest: %w", err)
+	}
+
+	if *send {
+		if err := sendDigest(s, d, *format, body.Bytes()); err != nil {
+			return err
+		}
+		fmt.Printf("Digest with %d posts sent to %s.\n", d.Total, strings.Join(s.cfg.SMTP.To, ", "))
+	}
+	if *outPath != "" {
+		if err := os.WriteFile(*outPath, body.Bytes(), 0644); err != nil {
+			return fmt.Errorf("couldn't write digest: %w", err)
+		}
+		fmt.Printf("Digest with %d posts written to %s.\n", d.Total, *outPath)
+	}
+	if !*send && *outPath == "" {
+		_, err = os.Stdout.Write(body.Bytes())
+		return err
+	}
+	return nil
+}
+
+func buildDigest(s *state, user database.User, since time.Time, groupBy string, limit int) (digest, error) {
+	posts, err := s.db.BrowsePostsForUser(context.Background(), database.BrowsePostsForUserParams{
+		SortBy:             "fetched",
+		UserID:             user.ID,
+		Since:              sql.NullTime{Time: since, Valid: true},
+		CollapseDuplicates: true,
+		PostLimit:          int32(limit),
+	})
+	if err != nil {
+		return digest{}, fmt.Errorf("failed to get posts: %w", err)
+	}
+
+	feedTags := make(map[uuid.UUID][]string)
+	if groupBy == "tag" {
+		follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+		if err != nil {
+			return digest{}, fmt.Errorf("failed to get feed follows: %w", err)
+		}
+		tags, err := followTags(s, user)
+		if err != nil {
+			return digest{}, err
+		}
+		for _, follow := range follows {
+			feedTags[follow.FeedID] = tags[follow.ID]
+		}
+	}
+
+	groups := make(map[string][]digestPost)
+	for _, post := range posts {
+		published := post.CreatedAt
+		if post.PublishedAt.Valid {
+			published = post.PublishedAt.Time
+		}
+		p := digestPost{
+			Title:       post.Title,
+			URL:         post.Url,
+			FeedName:    post.FeedName,
+			Summary:     htmltext.Summary(post.Description.String, digestSummaryLength),
+			PublishedAt: published.In(s.output.location),
+		}
+		keys := []string{post.FeedName}
+		if groupBy == "tag" {
+			keys = feedTags[post.FeedID]
+			if len(keys) == 0 {
+				keys = []string{"untagged"}
+			}
+		}
+		for _, key := range keys {
+			groups[key] = append(groups[key], p)
+		}
+	}
+
+	d := digest{
+		User:  user.Name,
+		Since: since.In(s.output.location),
+		Until: time.Now().In(s.output.location),
+		Total: len(posts),
+	}
+	for name, posts := range groups {
+		d.Groups = append(d.Groups, digestGroup{Name: name, Posts: posts})
+	}
+	slices.SortFunc(d.Groups, func(a, b digestGroup) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return d, nil
+}
+
+func sendDigest(s *state, d digest, format string, body []byte) error {
+	cfg := s.cfg.SMTP
+	if cfg == nil || cfg.Host == "" || cfg.From == "" || len(cfg.To) == 0 {
+		return fmt.Errorf("smtp isn't configured: set smtp.host, smtp.from and smtp.to in the config")
+	}
+	port := cfg.Port
+	if port == 0 {
+		port = 25
+	}
+	addr := net.JoinHostPort(cfg.Host, strconv.Itoa(port))
+
+	contentType := "text/plain; charset=utf-8"
+	if format == "html" {
+		contentType = "text/html; charset=utf-8"
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(cfg.To, ", "))
+	subject := fmt.Sprintf("gator digest for %s: %d new posts", d.User, d.Total)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", d.Until.Format(time.RFC1123Z))
+	fmt.Fprintf(&msg, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(&msg, "Content-Type: %s\r\n\r\n", contentType)
+	msg.Write(bytes.ReplaceAll(body, []byte("\n"), []byte("\r\n")))
+
+	var auth smtp.Auth
+	if cfg.Username != "" {
+		auth = smtp.PlainAuth("", cfg.Username, cfg.Password, cfg.Host)
+	}
+	if err := smtp.SendMail(addr, auth, cfg.From, cfg.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("couldn't send digest: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/config"
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// fakeSMTP accepts one message on a local port and sends what it received,
+// envelope and data, on the returned channel.
+func fakeSMTP(t *testing.T) (host string, port int, received <-chan smtpMessage) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan smtpMessage, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+
+		var msg smtpMessage
+		reply("220 localhost ESMTP fake")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			verb, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				reply("250 localhost")
+			case "MAIL":
+				msg.From = arg
+				reply("250 OK")
+			case "RCPT":
+				msg.To = append(msg.To, arg)
+				reply("250 OK")
+			case "DATA":
+				reply("354 End data with <CR><LF>.<CR><LF>")
+				var data bytes.Buffer
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(line, "."))
+				}
+				msg.Data = data.String()
+				reply("250 OK")
+			case "QUIT":
+				reply("221 Bye")
+				ch <- msg
+				return
+			default:
+				reply("250 OK")
+			}
+		}
+	}()
+
+	addr := ln.Addr().(*net.TCPAddr)
+	return addr.IP.String(), addr.Port, ch
+}
+
+type smtpMessage struct {
+	From string
+	To   []string
+	Data string
+}
+
+func TestSendDigest(t *testing.T) {
+	host, port, received := fakeSMTP(t)
+	s := &state{cfg: &config.Config{SMTP: &config.SMTPConfig{
+		Host: host,
+		Port: port,
+		From: "gator@example.com",
+		To:   []string{"zoë@example.com", "ops@example.com"},
+	}}}
+	d := digest{User: "Zoë", Total: 2, Until: time.Date(2026, 10, 19, 8, 0, 0, 0, time.UTC)}
+
+	if err := sendDigest(s, d, "text", []byte("line one\nline two\n.hidden dot\n")); err != nil {
+		t.Fatalf("sendDigest: %v", err)
+	}
+	var msg smtpMessage
+	select {
+	case msg = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fake server got no message")
+	}
+
+	if msg.From != "FROM:<gator@example.com>" || len(msg.To) != 2 {
+		t.Errorf("envelope = %q to %q", msg.From, msg.To)
+	}
+	parsed, err := mail.ReadMessage(strings.NewReader(msg.Data))
+	if err != nil {
+		t.Fatalf("the message doesn't parse: %v\n%s", err, msg.Data)
+	}
+	rawSubject := parsed.Header.Get("Subject")
+	for _, r := range rawSubject {
+		if r > 127 {
+			t.Fatalf("Subject header isn't encoded: %q", rawSubject)
+		}
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(rawSubject)
+	if err != nil {
+		t.Fatalf("decoding Subject %q: %v", rawSubject, err)
+	}
+	if want := "gator digest for Zoë: 2 new posts"; subject != want {
+		t.Errorf("Subject = %q, want %q", subject, want)
+	}
+	if got := parsed.Header.Get("Content-Type"); got != "text/plain; charset=utf-8" {
+		t.Errorf("Content-Type = %q", got)
+	}
+	body, err := io.ReadAll(parsed.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "line one\r\nline two\r\n.hidden dot\r\n"; string(body) != want {
+		t.Errorf("body = %q, want %q", body, want)
+	}
+}
+
+func TestSendDigestNotConfigured(t *testing.T) {
+	s := &state{cfg: &config.Config{}}
+	if err := sendDigest(s, digest{}, "text", nil); err == nil {
+		t.Error("sendDigest without smtp settings succeeded")
+	}
+}
+
+func TestDigestMarkdownEscapes(t *testing.T) {
+	d := digest{
+		User:  "ada_l",
+		Total: 1,
+		Groups: []digestGroup{{
+			Name: "C# weekly",
+			Posts: []digestPost{{
+				Title:   "Why *not* [link](evil)?",
+				URL:     "https://example.com/a (b)",
+				Summary: "Use `go vet` <b>now</b>",
+			}},
+		}},
+	}
+	var out bytes.Buffer
+	if err := digestMarkdownTemplate.Execute(&out, d); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`# Digest for ada\_l`,
+		`## C\# weekly`,
+		`- [Why \*not\* \[link\](evil)?](https://example.com/a%20%28b%29)`,
+		"Use \\`go vet\\` \\<b\\>now\\</b\\>",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("markdown digest is missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestDigestUsesTimezone(t *testing.T) {
+	s := newTestState(t)
+	s.output.location = time.FixedZone("UTC+9", 9*60*60)
+	user := createTestUser(t, s, "ada", roleMember, sql.NullString{String: "x", Valid: true})
+	feed := createTestFeed(t, s, "news", user)
+	_, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	createTestPost(t, s, feed, "Hello", time.Date(2026, 10, 12, 20, 0, 0, 0, time.UTC))
+
+	since := time.Date(2026, 10, 12, 18, 30, 0, 0, time.UTC)
+	d, err := buildDigest(s, user, since, "feed", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.Groups) != 1 || len(d.Groups[0].Posts) != 1 {
+		t.Fatalf("digest groups = %+v, want one post", d.Groups)
+	}
+	if got := d.Groups[0].Posts[0].PublishedAt; got.Location() != s.output.location || got.Hour() != 5 {
+		t.Errorf("post published at %v, want 05:00 UTC+9", got)
+	}
+	var out bytes.Buffer
+	if err := digestTextTemplate.Execute(&out, d); err != nil {
+		t.Fatal(err)
+	}
+	if want := "since 2026-10-13 03:30"; !strings.Contains(out.String(), want) {
+		t.Errorf("text digest is missing %q:\n%s", want, out.String())
+	}
+}
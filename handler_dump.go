@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/storage"
+)
+
+// handlerDump writes every user, feed, follow, post and per-user setting
+// to a versioned archive that restore can read back.
+func handlerDump(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	format := fs.String("format", "", "archive format: json or jsonl (default: from the file extension, else json)")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: %s [--format json|jsonl] [file]", cmd.Name)
+	}
+	if *format == "" {
+		*format = archiveJSON
+		if len(args) == 1 && strings.HasSuffix(args[0], ".jsonl") {
+			*format = archiveJSONL
+		}
+	}
+	if !slices.Contains([]string{archiveJSON, archiveJSONL}, *format) {
+		return fmt.Errorf("unknown archive format %q: use json or jsonl", *format)
+	}
+
+	if len(args) == 1 {
+		if err := writeArchiveFile(s, args[0], *format); err != nil {
+			return fmt.Errorf("couldn't write archive: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Database dumped to %s.\n", args[0])
+		return nil
+	}
+
+	a, err := buildArchive(s)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(os.Stdout)
+	if *format == archiveJSONL {
+		err = writeArchiveLines(w, a)
+	} else {
+		err = writeArchive(w, a)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't write archive: %w", err)
+	}
+	return w.Flush()
+}
+
+// handlerRestore loads an archive written by dump in a single transaction.
+// A completely empty database, with no users and no feeds, can be restored
+// into without logging in, since there is nobody to log in as; otherwise it
+// takes an admin and --merge.
+func handlerRestore(s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	merge := fs.Bool("merge", false, "add the archive to a database that already has data, keeping existing rows")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s [--merge] <file>", cmd.Name)
+	}
+	ctx := context.Background()
+
+	users, err := s.db.CountUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't count users: %w", err)
+	}
+	feeds, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get feeds: %w", err)
+	}
+	if users == 0 && len(feeds) > 0 {
+		return errors.New("the database has feeds but no users; restore without logging in needs an empty database")
+	}
+	if users > 0 {
+		user, err := currentUser(s)
+		if err != nil {
+			return err
+		}
+		if err := checkPermission(user, permAdmin); err != nil {
+			return err
+		}
+	}
+	if (users > 0 || len(feeds) > 0) && !*merge {
+		return errors.New("the database isn't empty; use --merge to add the archive to it")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	a, err := readArchive(f)
+	if err != nil {
+		return err
+	}
+
+	var records []restoreRecord
+	err = s.db.InTx(ctx, func(tx storage.Store) error {
+		records, err = restoreArchive(tx, a)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("%w; nothing was restored", err)
+	}
+
+	if err := s.printRecords(records); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Restored archive from %s (version %d, created %s).\n",
+		args[0], a.Version, a.CreatedAt.In(s.output.location).Format("2006-01-02 15:04"))
+	return nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// newTestArchive fills a new database with one of everything tags and
+// stars touch, and returns its archive.
+func newTestArchive(t *testing.T) archive {
+	t.Helper()
+	s := newTestState(t)
+	ctx := context.Background()
+	user := createTestUser(t, s, "ada", roleAdmin, sql.NullString{String: "x", Valid: true})
+	feed := createTestFeed(t, s, "news", user)
+	follow, err := s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := addFollowTags(s, user, follow.ID, []string{"go", "weekly"}); err != nil {
+		t.Fatal(err)
+	}
+	post := createTestPost(t, s, feed, "Hello, \"world\"\n", time.Now().UTC())
+	err = s.db.SetPostStarred(ctx, database.SetPostStarredParams{UserID: user.ID, PostID: post.ID, Starred: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	a, err := buildArchive(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return a
+}
+
+func TestArchiveRoundTrip(t *testing.T) {
+	a := newTestArchive(t)
+	want, err := json.Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, write := range map[string]func(*bytes.Buffer, archive) error{
+		"json":  func(b *bytes.Buffer, a archive) error { return writeArchive(b, a) },
+		"jsonl": func(b *bytes.Buffer, a archive) error { return writeArchiveLines(b, a) },
+	} {
+		var buf bytes.Buffer
+		if err := write(&buf, a); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		got, err := readArchive(&buf)
+		if err != nil {
+			t.Fatalf("%s: readArchive: %v", name, err)
+		}
+		gotJSON, err := json.Marshal(got)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(gotJSON, want) {
+			t.Errorf("%s round trip changed the archive:\n got %s\nwant %s", name, gotJSON, want)
+		}
+	}
+}
+
+func TestReadArchiveErrors(t *testing.T) {
+	for name, input := range map[string]string{
+		"empty":        "",
+		"not json":     "users: []",
+		"no version":   `{"users": []}`,
+		"too new":      `{"version": 999}`,
+		"unknown type": `{"type": "header", "version": 1}` + "\n" + `{"type": "secrets", "data": {}}`,
+		"bad row":      `{"type": "header", "version": 1}` + "\n" + `{"type": "users", "data": [1]}`,
+	} {
+		if _, err := readArchive(strings.NewReader(input)); err == nil {
+			t.Errorf("%s: readArchive succeeded, want an error", name)
+		}
+	}
+}
+
+func TestRestoreCountsExistingFollowTags(t *testing.T) {
+	a := newTestArchive(t)
+	s := newTestState(t)
+	counts := func() map[string]restoreRecord {
+		records, err := restoreArchive(s.db, a)
+		if err != nil {
+			t.Fatal(err)
+		}
+		byTable := make(map[string]restoreRecord)
+		for _, r := range records {
+			byTable[r.Table] = r
+		}
+		return byTable
+	}
+
+	if got := counts()["feed_follow_tags"]; got.Restored != 2 || got.Existing != 0 {
+		t.Errorf("first restore: feed_follow_tags = %+v, want 2 restored", got)
+	}
+	if got := counts()["feed_follow_tags"]; got.Restored != 0 || got.Existing != 2 {
+		t.Errorf("second restore: feed_follow_tags = %+v, want 2 existing", got)
+	}
+}
+
+func TestRestoreWithoutLoginNeedsEmptyDatabase(t *testing.T) {
+	s := newTestState(t)
+	_, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Name:      "orphan",
+		Url:       "https://orphan.example.com/feed",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "archive.json")
+	err = handlerRestore(s, command{Name: "restore", Args: []string{"--merge", path}})
+	if err == nil || !strings.Contains(err.Error(), "no users") {
+		t.Errorf("restore into a database with feeds but no users = %v, want a refusal", err)
+	}
+}
+
+func TestRestoreRejectsUnsupportedRegex(t *testing.T) {
+	a := newTestArchive(t)
+	a.PostRules = append(a.PostRules, archivePostRule{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    a.Users[0].ID,
+		Field:     "title",
+		MatchType: "regex",
+		Pattern:   `kube(?=r)`,
+		Action:    "mute",
+	})
+	s := newTestState(t)
+	if _, err := restoreArchive(s.db, a); err == nil || !strings.Contains(err.Error(), "regular expression") {
+		t.Errorf("restoring a rule SQLite can't run = %v, want an error", err)
+	}
+}
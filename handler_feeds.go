@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/storage"
+)
+
+// feedCheckTimeout bounds the test fetch setfeedurl does before saving.
+const feedCheckTimeout = 15 * time.Second
+
+// checkCanManageFeed lets the user who added a feed, or an admin, change
+// it. Feeds whose adder has been deleted belong to the admins.
+func checkCanManageFeed(actor database.User, feed database.Feed) error {
+	if feed.AddedBy.Valid && feed.AddedBy.UUID == actor.ID {
+		return nil
+	}
+	if hasPermission(actor, permAdmin) {
+		return nil
+	}
+	return fmt.Errorf("only the user who added feed %s or an admin can change it", feed.Name)
+}
+
+// confirmSharedFeedChange asks before changing a feed that users other
+// than actor follow, since the change affects them too.
+func confirmSharedFeedChange(s *state, actor database.User, feed database.Feed, action string, yes bool) error {
+	if yes {
+		return nil
+	}
+	followers, err := s.db.GetFeedFollowerNames(context.Background(), feed.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get followers: %w", err)
+	}
+	others := slices.DeleteFunc(followers, func(name string) bool { return name == actor.Name })
+	if len(others) == 0 {
+		return nil
+	}
+	ok, err := confirm(fmt.Sprintf("Feed %s is also followed by %s. %s for everyone?", feed.Name, joinAnd(others), action))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("cancelled; nothing was changed")
+	}
+	return nil
+}
+
+// handlerRemoveFeed deletes a feed with its posts and everyone's follows
+// of it.
+func handlerRemoveFeed(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "don't ask for confirmation when others follow the feed")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s <feed_url|feed_name> [--yes]", cmd.Name)
+	}
+	feed, err := lookupFeed(s, args[0])
+	if err != nil {
+		return err
+	}
+	if err := checkCanManageFeed(user, feed); err != nil {
+		return err
+	}
+	if err := confirmSharedFeedChange(s, user, feed, "Remove it and its posts", *yes); err != nil {
+		return err
+	}
+
+	if err := s.db.DeleteFeed(context.Background(), feed.ID); err != nil {
+		return fmt.Errorf("couldn't remove feed: %w", err)
+	}
+	fmt.Printf("Removed feed %s.\n", feed.Name)
+	return nil
+}
+
+func handlerRenameFeed(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "don't ask for confirmation when others follow the feed")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: %s <feed_url|feed_name> <new_name> [--yes]", cmd.Name)
+	}
+	newName := strings.TrimSpace(args[1])
+	if newName == "" {
+		return errors.New("feed names can't be empty")
+	}
+	feed, err := lookupFeed(s, args[0])
+	if err != nil {
+		return err
+	}
+	if err := checkCanManageFeed(user, feed); err != nil {
+		return err
+	}
+	// Commands look feeds up by name, so names must stay unambiguous.
+	if other, err := s.db.GetFeed(context.Background(), newName); err == nil && other.ID != feed.ID {
+		return fmt.Errorf("another feed is already called %s", newName)
+	} else if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("couldn't look up feed: %w", err)
+	}
+	if err := confirmSharedFeedChange(s, user, feed, "Rename it", *yes); err != nil {
+		return err
+	}
+
+	err = s.db.RenameFeed(context.Background(), database.RenameFeedParams{ID: feed.ID, Name: newName})
+	if err != nil {
+		return fmt.Errorf("couldn't rename feed: %w", err)
+	}
+	fmt.Printf("Renamed feed %s to %s.\n", feed.Name, newName)
+	return nil
+}
+
+// handlerSetFeedURL points a feed at a new address, after fetching it to
+// make sure there is a feed there.
+func handlerSetFeedURL(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "don't ask for confirmation when others follow the feed")
+	noCheck := fs.Bool("no-check", false, "save the URL without test-fetching it")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: %s <feed_url|feed_name> <new_url> [--yes] [--no-check]", cmd.Name)
+	}
+	feed, err := lookupFeed(s, args[0])
+	if err != nil {
+		return err
+	}
+	if err := checkCanManageFeed(user, feed); err != nil {
+		return err
+	}
+
+	newURL := s.normalizeURL(args[1])
+	if u, err := url.Parse(newURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http(s) URL", args[1])
+	}
+	if newURL == feed.Url {
+		fmt.Printf("Feed %s already uses %s.\n", feed.Name, newURL)
+		return nil
+	}
+	if other, err := s.db.GetFeedByURL(context.Background(), newURL); err == nil {
+		return fmt.Errorf("feed %s already uses %s", other.Name, newURL)
+	} else if err != sql.ErrNoRows {
+		return fmt.Errorf("couldn't look up feed: %w", err)
+	}
+	if !*noCheck {
+		ctx, cancel := context.WithTimeout(context.Background(), feedCheckTimeout)
+		defer cancel()
+		rss, err := fetchFeed(ctx, newURL)
+		if err != nil {
+			return fmt.Errorf("couldn't fetch a feed from %s: %w (use --no-check to save it anyway)", newURL, err)
+		}
+		fmt.Printf("Found %q with %d items.\n", rss.Channel.Title, len(rss.Channel.Item))
+	}
+	if err := confirmSharedFeedChange(s, user, feed, "Change its URL", *yes); err != nil {
+		return err
+	}
+
+	err = s.db.UpdateFeedURL(context.Background(), database.UpdateFeedURLParams{ID: feed.ID, Url: newURL})
+	if storage.IsUniqueViolation(err) {
+		return fmt.Errorf("another feed already uses %s", newURL)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't update feed URL: %w", err)
+	}
+	fmt.Printf("Feed %s now fetches from %s.\n", feed.Name, newURL)
+	return nil
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/migrate"
+	"github.com/Grumpster-Dev/gator/internal/storage"
+)
+
+type migrationRecord struct {
+	Version   int64      `json:"version"`
+	Name      string     `json:"name"`
+	AppliedAt *time.Time `json:"applied_at"`
+}
+
+// handlerMigrate applies and inspects the schema migrations built into the
+// binary. It runs without a logged-in user, since before the first
+// migration there are no users to log in as.
+func handlerMigrate(s *state, cmd command) error {
+	usage := fmt.Errorf("usage: %s up [--to N] | %s down [--to N] [--yes] | %s status | %s version", cmd.Name, cmd.Name, cmd.Name, cmd.Name)
+	if len(cmd.Args) == 0 {
+		return usage
+	}
+	sub := command{Name: cmd.Name + " " + cmd.Args[0], Args: cmd.Args[1:]}
+	switch cmd.Args[0] {
+	case "up":
+		return handlerMigrateUp(s, sub)
+	case "down":
+		return handlerMigrateDown(s, sub)
+	case "status":
+		return handlerMigrateStatus(s, sub)
+	case "version":
+		if len(sub.Args) != 0 {
+			return fmt.Errorf("usage: %s", sub.Name)
+		}
+		version, err := s.migrator.Version(context.Background())
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Database schema is at version %d; this gator expects %d.\n", version, s.migrator.Latest())
+		return nil
+	}
+	return usage
+}
+
+func handlerMigrateUp(s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	to := fs.Int64("to", s.migrator.Latest(), "migrate up to this version")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: %s [--to N]", cmd.Name)
+	}
+
+	applied := 0
+	err = s.migrator.Up(context.Background(), *to, func(m migrate.Migration) {
+		fmt.Printf("Applied %03d_%s\n", m.Version, m.Name)
+		applied++
+	})
+	if err != nil {
+		return err
+	}
+	if applied == 0 {
+		fmt.Println("Database schema is up to date.")
+	}
+	if *to < s.migrator.Latest() {
+		return nil
+	}
+	// Whoever can migrate the database is trusted to hand out the first
+	// logins to accounts that never had a password.
+	return issueAdminResets(s)
+}
+
+func handlerMigrateDown(s *state, cmd command) error {
+	ctx := context.Background()
+	version, err := s.migrator.Version(ctx)
+	if err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	to := fs.Int64("to", s.migrator.Previous(version), "roll back to this version; 0 drops everything")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: %s [--to N] [--yes]", cmd.Name)
+	}
+	if *to >= version {
+		fmt.Printf("Database schema is at version %d; nothing to roll back.\n", version)
+		return nil
+	}
+
+	if !*yes {
+		ok, err := confirm(fmt.Sprintf("Roll the schema back from version %d to %d? This can drop tables and data.", version, *to))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("migration cancelled")
+		}
+	}
+	return s.migrator.Down(ctx, *to, func(m migrate.Migration) {
+		fmt.Printf("Rolled back %03d_%s\n", m.Version, m.Name)
+	})
+}
+
+func handlerMigrateStatus(s *state, cmd command) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	statuses, err := s.migrator.Status(context.Background())
+	if err != nil {
+		return err
+	}
+	records := make([]migrationRecord, len(statuses))
+	for i, st := range statuses {
+		records[i] = migrationRecord{Version: st.Version, Name: st.Name, AppliedAt: st.AppliedAt}
+	}
+	return s.printRecords(records)
+}
+
+// checkSchema refuses to go on when the database is missing migrations
+// this binary relies on. A SQLite database is migrated instead: the file
+// is this gator's alone, and a new one should work without any setup.
+func checkSchema(s *state) error {
+	ctx := context.Background()
+	version, err := s.migrator.Version(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't check database schema: %w", err)
+	}
+	pending, err := s.migrator.Pending(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't check database schema: %w", err)
+	}
+	latest := s.migrator.Latest()
+	if len(pending) > 0 && s.engine == storage.SQLite {
+		if err := s.migrator.Up(ctx, latest, nil); err != nil {
+			return err
+		}
+		switch {
+		case version == 0:
+			fmt.Fprintln(os.Stderr, "Created a new SQLite database.")
+		case version < latest:
+			fmt.Fprintf(os.Stderr, "Migrated the database schema from version %d to %d.\n", version, latest)
+		default:
+			fmt.Fprintf(os.Stderr, "Applied %d skipped migrations to the database schema.\n", len(pending))
+		}
+		return nil
+	}
+	if version < latest {
+		return fmt.Errorf("database schema is at version %d but this gator needs version %d; run `gator migrate up` first", version, latest)
+	}
+	if len(pending) > 0 {
+		versions := make([]string, len(pending))
+		for i, m := range pending {
+			versions[i] = fmt.Sprintf("%d_%s", m.Version, m.Name)
+		}
+		return fmt.Errorf("database schema is missing migrations %s; run `gator migrate up` first", strings.Join(versions, ", "))
+	}
+	if version > latest {
+		fmt.Fprintf(os.Stderr, "Warning: database schema is at version %d, newer than this gator knows (%d); consider upgrading gator.\n", version, latest)
+	}
+	return nil
+}
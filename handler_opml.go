@@ -0,0 +1,272 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+type opml struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    opmlHead `xml:"head"`
+	Body    opmlBody `xml:"body"`
+}
+
+type opmlHead struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+type opmlBody struct {
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// opmlOutline is either a feed (it has an xmlUrl) or a folder of outlines.
+// Category holds comma-separated "/folder/subfolder" paths.
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string        `xml:"htmlUrl,attr,omitempty"`
+	Category string        `xml:"category,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+func (o opmlOutline) name() string {
+	if o.Title != "" {
+		return o.Title
+	}
+	return o.Text
+}
+
+// importStats counts what happened to each feed outline.
+type importStats struct {
+	created, followed, alreadyFollowed, duplicates, skipped int
+}
+
+// handlerImport follows every feed in an OPML file, creating feeds that
+// don't exist yet. Folders and categories become tags.
+func handlerImport(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <file.opml>", cmd.Name)
+	}
+	f, err := os.Open(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var doc opml
+	if err := xml.NewDecoder(f).Decode(&doc); err != nil {
+		return fmt.Errorf("couldn't parse OPML: %w", err)
+	}
+
+	var stats importStats
+	seen := make(map[string]bool)
+	if err := importOutlines(s, user, doc.Body.Outlines, nil, seen, &stats); err != nil {
+		return err
+	}
+	fmt.Printf("Followed %d feeds (%d new to gator); %d already followed, %d duplicates in the file, %d skipped.\n",
+		stats.followed, stats.created, stats.alreadyFollowed, stats.duplicates, stats.skipped)
+	return nil
+}
+
+func importOutlines(s *state, user database.User, outlines []opmlOutline, folders []string, seen map[string]bool, stats *importStats) error {
+	for _, outline := range outlines {
+		if outline.XMLURL == "" {
+			if len(outline.Outlines) == 0 {
+				continue
+			}
+			folder := strings.ToLower(strings.TrimSpace(outline.name()))
+			nested := folders
+			if folder != "" {
+				nested = append(slices.Clip(folders), folder)
+			}
+			if err := importOutlines(s, user, outline.Outlines, nested, seen, stats); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := importFeed(s, user, outline, folders, seen, stats); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func importFeed(s *state, user database.User, outline opmlOutline, folders []string, seen map[string]bool, stats *importStats) error {
+	ctx := context.Background()
+	feedURL := s.normalizeURL(outline.XMLURL)
+	if u, err := url.Parse(feedURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		fmt.Printf("Skipped %q: not an http(s) URL.\n", outline.XMLURL)
+		stats.skipped++
+		return nil
+	}
+	tags := outlineTags(outline, folders)
+
+	duplicate := seen[feedURL]
+	seen[feedURL] = true
+	if duplicate {
+		fmt.Printf("Duplicate entry for %s; merged its tags.\n", feedURL)
+		stats.duplicates++
+	}
+
+	feed, err := getFeedByURL(s, outline.XMLURL)
+	if err == sql.ErrNoRows {
+		name := strings.TrimSpace(outline.name())
+		if name == "" {
+			name = feedURL
+		}
+		feed, err = s.db.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			Name:      name,
+			Url:       feedURL,
+			AddedBy:   uuid.NullUUID{UUID: user.ID, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't create feed %s: %w", feedURL, err)
+		}
+		stats.created++
+	} else if err != nil {
+		return fmt.Errorf("couldn't look up feed %s: %w", feedURL, err)
+	}
+
+	follow, err := s.db.GetFeedFollowForUser(ctx, database.GetFeedFollowForUserParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err == sql.ErrNoRows {
+		created, err := s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't follow feed %s: %w", feedURL, err)
+		}
+		follow.ID = created.ID
+		stats.followed++
+	} else if err != nil {
+		return fmt.Errorf("couldn't get feed follow: %w", err)
+	} else if !duplicate {
+		fmt.Printf("Already following %s.\n", feed.Name)
+		stats.alreadyFollowed++
+	}
+
+	return addFollowTags(s, user, follow.ID, tags)
+}
+
+// outlineTags combines the folders enclosing an outline with the folders
+// named in its category attribute.
+func outlineTags(outline opmlOutline, folders []string) []string {
+	tags := slices.Clone(folders)
+	for _, path := range strings.Split(outline.Category, ",") {
+		for _, segment := range strings.Split(path, "/") {
+			segment = strings.ToLower(strings.TrimSpace(segment))
+			if segment != "" && !slices.Contains(tags, segment) {
+				tags = append(tags, segment)
+			}
+		}
+	}
+	return tags
+}
+
+// handlerExport writes the user's follows as OPML 2.0, to a file or to
+// stdout. Each feed goes in the folder of its first tag and lists all of
+// its tags as categories.
+func handlerExport(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %s [file]", cmd.Name)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+	tags, err := followTags(s, user)
+	if err != nil {
+		return err
+	}
+
+	doc := opml{
+		Version: "2.0",
+		Head: opmlHead{
+			Title:       fmt.Sprintf("gator subscriptions for %s", user.Name),
+			DateCreated: time.Now().UTC().Format(time.RFC1123Z),
+		},
+	}
+	folders := make(map[string]*opmlOutline)
+	var folderNames []string
+	var untagged []opmlOutline
+	for _, follow := range follows {
+		outline := opmlOutline{
+			Text:   follow.FeedName,
+			Title:  follow.FeedName,
+			Type:   "rss",
+			XMLURL: follow.FeedUrl,
+		}
+		followTags := tags[follow.ID]
+		if len(followTags) == 0 {
+			untagged = append(untagged, outline)
+			continue
+		}
+		categories := make([]string, len(followTags))
+		for i, tag := range followTags {
+			categories[i] = "/" + tag
+		}
+		outline.Category = strings.Join(categories, ",")
+
+		folder, ok := folders[followTags[0]]
+		if !ok {
+			folder = &opmlOutline{Text: followTags[0], Title: followTags[0]}
+			folders[followTags[0]] = folder
+			folderNames = append(folderNames, followTags[0])
+		}
+		folder.Outlines = append(folder.Outlines, outline)
+	}
+	slices.Sort(folderNames)
+	for _, name := range folderNames {
+		doc.Body.Outlines = append(doc.Body.Outlines, *folders[name])
+	}
+	doc.Body.Outlines = append(doc.Body.Outlines, untagged...)
+
+	var w io.Writer = os.Stdout
+	if len(cmd.Args) == 1 {
+		f, err := os.Create(cmd.Args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("couldn't write OPML: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return err
+	}
+	if len(cmd.Args) == 1 {
+		fmt.Fprintf(os.Stderr, "Exported %d feeds to %s.\n", len(follows), cmd.Args[0])
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+)
+
+// prefKey names a preference. Handlers read preferences through s.pref
+// with one of the constants below, so a misspelt name doesn't compile.
+type prefKey string
+
+const (
+	prefBrowseLimit      prefKey = "browse_limit"
+	prefSort             prefKey = "sort"
+	prefTimezone         prefKey = "timezone"
+	prefOutput           prefKey = "output"
+	prefShowDescriptions prefKey = "show_descriptions"
+)
+
+// preference is a per-user default. Handlers read it through s.pref, which
+// falls back to def when the user hasn't set it.
+type preference struct {
+	name        prefKey
+	def         string
+	description string
+	validate    func(string) error
+}
+
+var preferences = []preference{
+	{prefBrowseLimit, "2", "number of posts browse shows", validatePositiveInt},
+	{prefSort, "published", "browse sort order: published or fetched", validateOneOf("published", "fetched")},
+	{prefTimezone, "Local", "time zone for dates in output, digests and the tui, e.g. Europe/Berlin", validateTimezone},
+	{prefOutput, outputTable, "default output format: table, json, jsonl or csv", validateOneOf(outputTable, outputJSON, outputJSONL, outputCSV)},
+	{prefShowDescriptions, "false", "show post descriptions in browse: true or false", validateBool},
+}
+
+func validatePositiveInt(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return fmt.Errorf("%q is not a positive number", value)
+	}
+	return nil
+}
+
+func validateOneOf(values ...string) func(string) error {
+	return func(value string) error {
+		if !slices.Contains(values, value) {
+			return fmt.Errorf("%q is not one of %s", value, strings.Join(values, ", "))
+		}
+		return nil
+	}
+}
+
+func validateTimezone(value string) error {
+	_, err := time.LoadLocation(value)
+	return err
+}
+
+func validateBool(value string) error {
+	_, err := strconv.ParseBool(value)
+	return err
+}
+
+func lookupPreference(name string) (preference, error) {
+	for _, p := range preferences {
+		if string(p.name) == name {
+			return p, nil
+		}
+	}
+	names := make([]string, len(preferences))
+	for i, p := range preferences {
+		names[i] = string(p.name)
+	}
+	return preference{}, fmt.Errorf("unknown preference %q: valid preferences are %s", name, strings.Join(names, ", "))
+}
+
+// loadPreferences reads user's preferences into s and applies the ones
+// that affect output. Global flags given on the command line still win.
+func loadPreferences(s *state, user database.User) error {
+	rows, err := s.db.GetUserPreferences(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't load preferences: %w", err)
+	}
+	s.prefs = make(map[prefKey]string, len(rows))
+	for _, row := range rows {
+		s.prefs[prefKey(row.Key)] = row.Value
+	}
+
+	if !s.output.formatSet {
+		s.output.format = s.pref(prefOutput)
+	}
+	if loc, err := time.LoadLocation(s.pref(prefTimezone)); err == nil {
+		s.output.location = loc
+	}
+	return nil
+}
+
+// pref returns the user's value for a preference, or its default. Values
+// were validated when they were set, but a stale or hand-edited value
+// falls back to the default.
+func (s *state) pref(key prefKey) string {
+	p, err := lookupPreference(string(key))
+	if err != nil {
+		return ""
+	}
+	if value, ok := s.prefs[key]; ok && p.validate(value) == nil {
+		return value
+	}
+	return p.def
+}
+
+func (s *state) prefInt(key prefKey) int {
+	n, _ := strconv.Atoi(s.pref(key))
+	return n
+}
+
+func (s *state) prefBool(key prefKey) bool {
+	b, _ := strconv.ParseBool(s.pref(key))
+	return b
+}
+
+type prefRecord struct {
+	Name        string `json:"name"`
+	Value       string `json:"value"`
+	Default     bool   `json:"default"`
+	Description string `json:"description" table:"-"`
+}
+
+// handlerPrefs reads and changes the current user's preferences.
+func handlerPrefs(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s get [name] | %s set <name> <value> | %s unset <name>", cmd.Name, cmd.Name, cmd.Name)
+	if len(cmd.Args) == 0 {
+		return usage
+	}
+	sub := command{Name: cmd.Name + " " + cmd.Args[0], Args: cmd.Args[1:]}
+	switch cmd.Args[0] {
+	case "get":
+		return handlerPrefsGet(s, sub, user)
+	case "set":
+		return handlerPrefsSet(s, sub, user)
+	case "unset":
+		return handlerPrefsUnset(s, sub, user)
+	}
+	return usage
+}
+
+func handlerPrefsGet(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) > 1 {
+		return fmt.Errorf("usage: %s [name]", cmd.Name)
+	}
+	if len(cmd.Args) == 1 {
+		p, err := lookupPreference(cmd.Args[0])
+		if err != nil {
+			return err
+		}
+		fmt.Println(s.pref(p.name))
+		return nil
+	}
+
+	records := make([]prefRecord, len(preferences))
+	for i, p := range preferences {
+		_, set := s.prefs[p.name]
+		records[i] = prefRecord{
+			Name:        string(p.name),
+			Value:       s.pref(p.name),
+			Default:     !set,
+			Description: p.description,
+		}
+	}
+	return s.printRecords(records)
+}
+
+func handlerPrefsSet(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 2 {
+		return fmt.Errorf("usage: %s <name> <value>", cmd.Name)
+	}
+	p, err := lookupPreference(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	value := cmd.Args[1]
+	if err := p.validate(value); err != nil {
+		return fmt.Errorf("invalid %s: %w", p.name, err)
+	}
+	err = s.db.SetUserPreference(context.Background(), database.SetUserPreferenceParams{
+		UserID:    user.ID,
+		Key:       string(p.name),
+		Value:     value,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't save preference: %w", err)
+	}
+	fmt.Printf("Set %s to %s.\n", p.name, value)
+	return nil
+}
+
+func handlerPrefsUnset(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <name>", cmd.Name)
+	}
+	p, err := lookupPreference(cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	_, err = s.db.DeleteUserPreference(context.Background(), database.DeleteUserPreferenceParams{
+		UserID: user.ID,
+		Key:    string(p.name),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't reset preference: %w", err)
+	}
+	fmt.Printf("Reset %s to its default, %s.\n", p.name, p.def)
+	return nil
+}
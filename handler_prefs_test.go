@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestPrefFallsBackToDefault(t *testing.T) {
+	s := &state{prefs: map[prefKey]string{
+		prefBrowseLimit: "25",
+		prefSort:        "sideways",
+	}}
+	if got := s.prefInt(prefBrowseLimit); got != 25 {
+		t.Errorf("browse_limit = %d, want 25", got)
+	}
+	if got := s.pref(prefSort); got != "published" {
+		t.Errorf("invalid sort value gave %q, want the default", got)
+	}
+	if got := s.prefBool(prefShowDescriptions); got {
+		t.Error("unset show_descriptions is true, want the default false")
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/storage"
+)
+
+// handlerReset deletes data in bulk. With no scope flags it deletes every
+// feed and every user; between them they cascade to everything else.
+// Feeds outlive the user who added them, so deleting users isn't enough.
+func handlerReset(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	posts := fs.Bool("posts", false, "delete all posts, keeping users, feeds and follows")
+	userName := fs.String("user", "", "delete one user and everything they own")
+	orphans := fs.Bool("feeds-without-followers", false, "delete feeds nobody follows, unless someone starred one of their posts")
+	snapshot := fs.String("snapshot", "", "write a JSON snapshot of the database to this file first")
+	yes := fs.Bool("yes", false, "don't ask for confirmation")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: %s [--posts] [--user name] [--feeds-without-followers] [--snapshot file] [--yes]", cmd.Name)
+	}
+	ctx := context.Background()
+
+	var target database.User
+	var scope []string
+	if *posts {
+		scope = append(scope, "all posts")
+	}
+	if *userName != "" {
+		target, err = s.db.GetUser(ctx, *userName)
+		if err != nil {
+			return fmt.Errorf("couldn't find user %s: %w", *userName, err)
+		}
+		scope = append(scope, fmt.Sprintf("user %s", target.Name))
+	}
+	if *orphans {
+		scope = append(scope, "feeds without followers")
+	}
+	full := len(scope) == 0
+	if full {
+		scope = append(scope, "ALL users, feeds, follows and posts")
+	}
+
+	if !*yes {
+		ok, err := confirm(fmt.Sprintf("This will permanently delete %s. Continue?", joinAnd(scope)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.New("reset cancelled")
+		}
+	}
+
+	if *snapshot != "" {
+		if err := writeArchiveFile(s, *snapshot, archiveJSON); err != nil {
+			return fmt.Errorf("couldn't write snapshot, nothing was deleted: %w", err)
+		}
+		fmt.Printf("Snapshot written to %s.\n", *snapshot)
+	}
+
+	if full {
+		err := s.db.InTx(ctx, func(tx storage.Store) error {
+			if _, err := tx.DeleteAllFeeds(ctx); err != nil {
+				return err
+			}
+			return tx.DeleteUsers(ctx)
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't reset database: %w", err)
+		}
+		if err := s.cfg.ClearSession(); err != nil {
+			return fmt.Errorf("failed to update config: %w", err)
+		}
+		fmt.Println("Database has been reset to its initial state.")
+		return nil
+	}
+
+	if *posts {
+		n, err := s.db.DeleteAllPosts(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't delete posts: %w", err)
+		}
+		fmt.Printf("Deleted %d posts.\n", n)
+	}
+	if *userName != "" {
+		if err := deleteUser(s, user, target); err != nil {
+			return err
+		}
+	}
+	if *orphans {
+		// The same collection unfollow does, so starred posts survive.
+		n, err := s.db.DeleteUnusedFeeds(ctx)
+		if err != nil {
+			return fmt.Errorf("couldn't delete feeds: %w", err)
+		}
+		fmt.Printf("Deleted %d feeds without followers.\n", n)
+	}
+	return nil
+}
+
+// joinAnd joins items as "a, b and c".
+func joinAnd(items []string) string {
+	if len(items) == 1 {
+		return items[0]
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
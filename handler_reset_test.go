@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+)
+
+func TestResetFeedsWithoutFollowersKeepsStarredPosts(t *testing.T) {
+	s := newTestState(t)
+	ctx := context.Background()
+	admin := createTestUser(t, s, "admin", roleAdmin, sql.NullString{String: "x", Valid: true})
+	orphan := createTestFeed(t, s, "orphan", admin)
+	starred := createTestFeed(t, s, "starred", admin)
+	post := createTestPost(t, s, starred, "Keep me", time.Now().UTC())
+	err := s.db.SetPostStarred(ctx, database.SetPostStarredParams{UserID: admin.ID, PostID: post.ID, Starred: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = handlerReset(s, command{Name: "reset", Args: []string{"--feeds-without-followers", "--yes"}}, admin)
+	if err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if _, err := s.db.GetFeedByURL(ctx, orphan.Url); err != sql.ErrNoRows {
+		t.Errorf("feed without followers or stars survived (err %v)", err)
+	}
+	if _, err := s.db.GetFeedByURL(ctx, starred.Url); err != nil {
+		t.Errorf("feed with a starred post was deleted: %v", err)
+	}
+}
+
+func TestFullReset(t *testing.T) {
+	s := newTestState(t)
+	ctx := context.Background()
+	admin := createTestUser(t, s, "admin", roleAdmin, sql.NullString{String: "x", Valid: true})
+	feed := createTestFeed(t, s, "news", admin)
+	createTestPost(t, s, feed, "Hello", time.Now().UTC())
+
+	if err := handlerReset(s, command{Name: "reset", Args: []string{"--yes"}}, admin); err != nil {
+		t.Fatalf("reset: %v", err)
+	}
+	if users, err := s.db.CountUsers(ctx); err != nil || users != 0 {
+		t.Errorf("after reset, %d users (err %v), want none", users, err)
+	}
+	if feeds, err := s.db.GetFeeds(ctx); err != nil || len(feeds) != 0 {
+		t.Errorf("after reset, %d feeds (err %v), want none", len(feeds), err)
+	}
+	if posts, err := s.db.ListPostURLs(ctx); err != nil || len(posts) != 0 {
+		t.Errorf("after reset, %d posts (err %v), want none", len(posts), err)
+	}
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+// retentionPolicy limits the posts kept for a feed. Zero means no limit.
+type retentionPolicy struct {
+	maxAgeDays int
+	maxPosts   int
+}
+
+// feedRetention returns the policy for feed: its own limits where set,
+// otherwise the defaults from the config file.
+func (s *state) feedRetention(feed database.Feed) retentionPolicy {
+	var policy retentionPolicy
+	if s.cfg.Retention != nil {
+		policy = retentionPolicy{maxAgeDays: s.cfg.Retention.MaxAgeDays, maxPosts: s.cfg.Retention.MaxPosts}
+	}
+	if feed.RetentionDays.Valid {
+		policy.maxAgeDays = int(feed.RetentionDays.Int32)
+	}
+	if feed.RetentionMaxPosts.Valid {
+		policy.maxPosts = int(feed.RetentionMaxPosts.Int32)
+	}
+	return policy
+}
+
+type pruneRecord struct {
+	Feed string `json:"feed"`
+	// Expired posts are over the feed's age or count limit; Kept are the
+	// expired ones that are starred or still unread by a follower.
+	Expired int   `json:"expired"`
+	Kept    int   `json:"kept"`
+	Pruned  int64 `json:"pruned"`
+}
+
+// prunePosts deletes posts that are over their feed's retention limits.
+// Starred posts and posts a follower hasn't read yet are kept unless force
+// is set. With dryRun nothing is deleted and Pruned counts what would be.
+func prunePosts(s *state, dryRun, force bool) ([]pruneRecord, error) {
+	ctx := context.Background()
+	feeds, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get feeds: %w", err)
+	}
+
+	var records []pruneRecord
+	for _, feed := range feeds {
+		policy := s.feedRetention(feed)
+		if policy.maxAgeDays == 0 && policy.maxPosts == 0 {
+			continue
+		}
+		params := database.ListExpiredPostsParams{FeedID: feed.ID}
+		if policy.maxAgeDays > 0 {
+			params.Cutoff = sql.NullTime{Time: time.Now().UTC().AddDate(0, 0, -policy.maxAgeDays), Valid: true}
+		}
+		if policy.maxPosts > 0 {
+			params.MaxPosts = sql.NullInt64{Int64: int64(policy.maxPosts), Valid: true}
+		}
+		expired, err := s.db.ListExpiredPosts(ctx, params)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't find expired posts in %s: %w", feed.Name, err)
+		}
+		if len(expired) == 0 {
+			continue
+		}
+
+		record := pruneRecord{Feed: feed.Name, Expired: len(expired)}
+		var ids []uuid.UUID
+		for _, post := range expired {
+			if post.Protected && !force {
+				record.Kept++
+				continue
+			}
+			ids = append(ids, post.ID)
+		}
+		if dryRun || len(ids) == 0 {
+			record.Pruned = int64(len(ids))
+		} else {
+			record.Pruned, err = s.db.DeletePosts(ctx, ids)
+			if err != nil {
+				return nil, fmt.Errorf("couldn't prune posts in %s: %w", feed.Name, err)
+			}
+		}
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+func handlerPrune(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report what would be pruned without deleting anything")
+	force := fs.Bool("force", false, "also prune starred posts and posts followers haven't read")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: %s [--dry-run] [--force]", cmd.Name)
+	}
+
+	records, err := prunePosts(s, *dryRun, *force)
+	if err != nil {
+		return err
+	}
+	if len(records) == 0 {
+		fmt.Println("No posts are over their retention limits.")
+		return nil
+	}
+	if err := s.printRecords(records); err != nil {
+		return err
+	}
+	var pruned int64
+	for _, r := range records {
+		pruned += r.Pruned
+	}
+	if *dryRun {
+		fmt.Printf("Dry run: would prune %d posts.\n", pruned)
+	} else {
+		fmt.Printf("Pruned %d posts.\n", pruned)
+	}
+	return nil
+}
+
+type retentionRecord struct {
+	Feed       string `json:"feed"`
+	MaxAgeDays string `json:"max_age_days"`
+	MaxPosts   string `json:"max_posts"`
+	Override   bool   `json:"override"`
+}
+
+// handlerRetention shows and changes per-feed retention limits. The
+// defaults live in the config file under retention.max_age_days and
+// retention.max_posts.
+func handlerRetention(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s list | %s set <feed_url|feed_name> [--days N] [--max-posts N] | %s unset <feed_url|feed_name>", cmd.Name, cmd.Name, cmd.Name)
+	if len(cmd.Args) == 0 {
+		return usage
+	}
+	sub := command{Name: cmd.Name + " " + cmd.Args[0], Args: cmd.Args[1:]}
+	switch cmd.Args[0] {
+	case "list":
+		return handlerRetentionList(s, sub)
+	case "set":
+		return handlerRetentionSet(s, sub)
+	case "unset":
+		return handlerRetentionUnset(s, sub)
+	}
+	return usage
+}
+
+func handlerRetentionList(s *state, cmd command) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get feeds: %w", err)
+	}
+	records := make([]retentionRecord, len(feeds))
+	for i, feed := range feeds {
+		policy := s.feedRetention(feed)
+		records[i] = retentionRecord{
+			Feed:       feed.Name,
+			MaxAgeDays: retentionLimit(policy.maxAgeDays),
+			MaxPosts:   retentionLimit(policy.maxPosts),
+			Override:   feed.RetentionDays.Valid || feed.RetentionMaxPosts.Valid,
+		}
+	}
+	return s.printRecords(records)
+}
+
+func retentionLimit(n int) string {
+	if n == 0 {
+		return "none"
+	}
+	return strconv.Itoa(n)
+}
+
+func handlerRetentionSet(s *state, cmd command) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	days := fs.Int("days", -1, "prune posts older than this many days; 0 for no limit")
+	maxPosts := fs.Int("max-posts", -1, "keep at most this many posts; 0 for no limit")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 || (*days < 0 && *maxPosts < 0) {
+		return fmt.Errorf("usage: %s <feed_url|feed_name> [--days N] [--max-posts N]", cmd.Name)
+	}
+	feed, err := lookupFeed(s, args[0])
+	if err != nil {
+		return err
+	}
+
+	params := database.SetFeedRetentionParams{
+		ID:                feed.ID,
+		RetentionDays:     feed.RetentionDays,
+		RetentionMaxPosts: feed.RetentionMaxPosts,
+	}
+	if *days >= 0 {
+		params.RetentionDays = sql.NullInt32{Int32: int32(*days), Valid: true}
+	}
+	if *maxPosts >= 0 {
+		params.RetentionMaxPosts = sql.NullInt32{Int32: int32(*maxPosts), Valid: true}
+	}
+	if err := s.db.SetFeedRetention(context.Background(), params); err != nil {
+		return fmt.Errorf("couldn't set retention: %w", err)
+	}
+	feed.RetentionDays, feed.RetentionMaxPosts = params.RetentionDays, params.RetentionMaxPosts
+	policy := s.feedRetention(feed)
+	fmt.Printf("Feed %s: max_age_days %s, max_posts %s.\n",
+		feed.Name, retentionLimit(policy.maxAgeDays), retentionLimit(policy.maxPosts))
+	return nil
+}
+
+func handlerRetentionUnset(s *state, cmd command) error {
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <feed_url|feed_name>", cmd.Name)
+	}
+	feed, err := lookupFeed(s, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	if err := s.db.SetFeedRetention(context.Background(), database.SetFeedRetentionParams{ID: feed.ID}); err != nil {
+		return fmt.Errorf("couldn't reset retention: %w", err)
+	}
+	fmt.Printf("Feed %s now uses the default retention policy.\n", feed.Name)
+	return nil
+}
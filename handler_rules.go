@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+var (
+	ruleActions = []string{"mute", "highlight"}
+	ruleFields  = []string{"any", "title", "description", "author", "category"}
+)
+
+type ruleRecord struct {
+	ID        uuid.UUID `json:"id"`
+	Action    string    `json:"action"`
+	Field     string    `json:"field"`
+	MatchType string    `json:"match_type"`
+	Pattern   string    `json:"pattern"`
+	Feed      string    `json:"feed"`
+	CreatedAt time.Time `json:"created_at" table:"-"`
+}
+
+// handlerRule manages the user's mute and highlight rules. Rules are applied
+// by the browse and search queries themselves, so muted posts never leave
+// the database.
+func handlerRule(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s add <mute|highlight> <pattern> [--field field] [--regex] [--feed url|name] | %s list | %s rm <id>", cmd.Name, cmd.Name, cmd.Name)
+	if len(cmd.Args) == 0 {
+		return usage
+	}
+	sub := command{Name: cmd.Name + " " + cmd.Args[0], Args: cmd.Args[1:]}
+	switch cmd.Args[0] {
+	case "add":
+		return handlerRuleAdd(s, sub, user)
+	case "list":
+		return handlerRuleList(s, sub, user)
+	case "rm":
+		return handlerRuleRemove(s, sub, user)
+	}
+	return usage
+}
+
+func handlerRuleAdd(s *state, cmd command, user database.User) error {
+	if err := checkPermission(user, permWrite); err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	field := fs.String("field", "any", "post field to match: any, title, description, author or category")
+	regex := fs.Bool("regex", false, "treat the pattern as a case-insensitive regular expression")
+	feedRef := fs.String("feed", "", "only apply the rule to this feed (url or name)")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 2 {
+		return fmt.Errorf("usage: %s <mute|highlight> <pattern> [--field field] [--regex] [--feed url|name]", cmd.Name)
+	}
+	action, pattern := args[0], args[1]
+	if !slices.Contains(ruleActions, action) {
+		return fmt.Errorf("invalid rule action %q: use mute or highlight", action)
+	}
+	if !slices.Contains(ruleFields, *field) {
+		return fmt.Errorf("invalid rule field %q: use any, title, description, author or category", *field)
+	}
+	if pattern == "" {
+		return fmt.Errorf("rule pattern can't be empty")
+	}
+
+	matchType := "keyword"
+	if *regex {
+		matchType = "regex"
+		// Let the database compile the pattern, since it's the database that
+		// runs it.
+		_, err := s.db.CheckRulePattern(context.Background(), database.CheckRulePatternParams{
+			MatchType: matchType,
+			Pattern:   pattern,
+		})
+		if err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+	}
+
+	params := database.CreatePostRuleParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		Field:     *field,
+		MatchType: matchType,
+		Pattern:   pattern,
+		Action:    action,
+	}
+	if *feedRef != "" {
+		feed, err := lookupFeed(s, *feedRef)
+		if err != nil {
+			return err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+
+	rule, err := s.db.CreatePostRule(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("couldn't create rule: %w", err)
+	}
+	fmt.Printf("Rule %s created.\n", rule.ID)
+	return nil
+}
+
+func handlerRuleList(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	rules, err := s.db.GetPostRulesForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get rules: %w", err)
+	}
+	records := make([]ruleRecord, len(rules))
+	for i, rule := range rules {
+		feed := "(all feeds)"
+		if rule.FeedName.Valid {
+			feed = rule.FeedName.String
+		}
+		records[i] = ruleRecord{
+			ID:        rule.ID,
+			Action:    rule.Action,
+			Field:     rule.Field,
+			MatchType: rule.MatchType,
+			Pattern:   rule.Pattern,
+			Feed:      feed,
+			CreatedAt: rule.CreatedAt,
+		}
+	}
+	return s.printRecords(records)
+}
+
+func handlerRuleRemove(s *state, cmd command, user database.User) error {
+	if err := checkPermission(user, permWrite); err != nil {
+		return err
+	}
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <id>", cmd.Name)
+	}
+	id, err := uuid.Parse(cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("invalid rule id: %w", err)
+	}
+	removed, err := s.db.DeletePostRule(context.Background(), database.DeletePostRuleParams{
+		ID:     id,
+		UserID: user.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't remove rule: %w", err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("no rule with id %s", id)
+	}
+	fmt.Printf("Rule %s removed.\n", id)
+	return nil
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+type savedSearchRecord struct {
+	Name      string    `json:"name"`
+	Query     string    `json:"query"`
+	Feeds     []string  `json:"feeds"`
+	Tags      []string  `json:"tags"`
+	Author    string    `json:"author"`
+	Window    string    `json:"window"`
+	CreatedAt time.Time `json:"created_at" table:"-"`
+}
+
+// handlerSaved manages saved searches: named browse filters that show up in
+// `following` as virtual feeds and can be read with `browse --saved`.
+func handlerSaved(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s add <name> [keywords...] [--feed url|name]... [--tag tag]... [--author name] [--window duration] | %s list | %s rm <name>", cmd.Name, cmd.Name, cmd.Name)
+	if len(cmd.Args) == 0 {
+		return usage
+	}
+	sub := command{Name: cmd.Name + " " + cmd.Args[0], Args: cmd.Args[1:]}
+	switch cmd.Args[0] {
+	case "add":
+		return handlerSavedAdd(s, sub, user)
+	case "list":
+		return handlerSavedList(s, sub, user)
+	case "rm":
+		return handlerSavedRemove(s, sub, user)
+	}
+	return usage
+}
+
+func handlerSavedAdd(s *state, cmd command, user database.User) error {
+	if err := checkPermission(user, permWrite); err != nil {
+		return err
+	}
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	var feedRefs, tags stringList
+	fs.Var(&feedRefs, "feed", "limit to this feed (url or name); may be repeated")
+	fs.Var(&tags, "tag", "limit to feeds with this tag; may be repeated")
+	author := fs.String("author", "", "only match posts whose author contains this text")
+	window := fs.Duration("window", 0, "only match posts from this far back, e.g. 168h")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s <name> [keywords...] [--feed url|name]... [--tag tag]... [--author name] [--window duration]", cmd.Name)
+	}
+	if *window < 0 {
+		return fmt.Errorf("window must be positive")
+	}
+
+	params := database.CreateSavedSearchParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		UserID:    user.ID,
+		Name:      args[0],
+		FeedIds:   []uuid.UUID{},
+		Tags:      []string{},
+	}
+	if query := strings.Join(args[1:], " "); query != "" {
+		params.Query = sql.NullString{String: query, Valid: true}
+	}
+	for _, ref := range feedRefs {
+		feed, err := lookupFeed(s, ref)
+		if err != nil {
+			return err
+		}
+		params.FeedIds = append(params.FeedIds, feed.ID)
+	}
+	if len(tags) > 0 {
+		params.Tags, err = tagNames(tags)
+		if err != nil {
+			return err
+		}
+	}
+	if *author != "" {
+		params.Author = sql.NullString{String: *author, Valid: true}
+	}
+	if *window > 0 {
+		params.WindowSeconds = sql.NullInt64{Int64: int64(window.Seconds()), Valid: true}
+	}
+
+	search, err := s.db.CreateSavedSearch(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("couldn't save search: %w", err)
+	}
+	fmt.Printf("Saved search %s created. Read it with: browse --saved %s\n", search.Name, search.Name)
+	return nil
+}
+
+func handlerSavedList(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 0 {
+		return fmt.Errorf("usage: %s", cmd.Name)
+	}
+	searches, err := s.db.GetSavedSearchesForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get saved searches: %w", err)
+	}
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		return fmt.Errorf("failed to get feeds: %w", err)
+	}
+	feedNames := make(map[uuid.UUID]string, len(feeds))
+	for _, feed := range feeds {
+		feedNames[feed.ID] = feed.Name
+	}
+
+	records := make([]savedSearchRecord, len(searches))
+	for i, search := range searches {
+		record := savedSearchRecord{
+			Name:      search.Name,
+			Query:     search.Query.String,
+			Feeds:     []string{},
+			Tags:      search.Tags,
+			Author:    search.Author.String,
+			CreatedAt: search.CreatedAt,
+		}
+		for _, id := range search.FeedIds {
+			if name, ok := feedNames[id]; ok {
+				record.Feeds = append(record.Feeds, name)
+			}
+		}
+		if search.WindowSeconds.Valid {
+			record.Window = (time.Duration(search.WindowSeconds.Int64) * time.Second).String()
+		}
+		records[i] = record
+	}
+	return s.printRecords(records)
+}
+
+func handlerSavedRemove(s *state, cmd command, user database.User) error {
+	if err := checkPermission(user, permWrite); err != nil {
+		return err
+	}
+	if len(cmd.Args) != 1 {
+		return fmt.Errorf("usage: %s <name>", cmd.Name)
+	}
+	removed, err := s.db.DeleteSavedSearch(context.Background(), database.DeleteSavedSearchParams{
+		UserID: user.ID,
+		Name:   cmd.Args[0],
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't remove saved search: %w", err)
+	}
+	if removed == 0 {
+		return fmt.Errorf("no saved search named %q", cmd.Args[0])
+	}
+	fmt.Printf("Saved search %s removed.\n", cmd.Args[0])
+	return nil
+}
+
+// savedSearchSince turns a saved search's window into the oldest post time
+// it matches.
+func savedSearchSince(search database.SavedSearch) sql.NullTime {
+	if !search.WindowSeconds.Valid {
+		return sql.NullTime{}
+	}
+	window := time.Duration(search.WindowSeconds.Int64) * time.Second
+	return sql.NullTime{Time: time.Now().UTC().Add(-window), Valid: true}
+}
+
+// savedSearchRecords lists the user's saved searches as virtual feeds for
+// `following`, each with its own unread count.
+func savedSearchRecords(s *state, user database.User) ([]followRecord, error) {
+	searches, err := s.db.GetSavedSearchesForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get saved searches: %w", err)
+	}
+	records := make([]followRecord, len(searches))
+	for i, search := range searches {
+		unread, err := s.db.CountUnreadPostsForUser(context.Background(), database.CountUnreadPostsForUserParams{
+			UserID:             user.ID,
+			Query:              search.Query,
+			FeedIds:            search.FeedIds,
+			Tags:               search.Tags,
+			Author:             search.Author,
+			Since:              savedSearchSince(search),
+			SortBy:             s.pref(prefSort),
+			CollapseDuplicates: true,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("couldn't count posts for saved search %s: %w", search.Name, err)
+		}
+		records[i] = followRecord{
+			FeedName:   search.Name,
+			FeedURL:    "saved:" + search.Name,
+			Tags:       search.Tags,
+			Unread:     unread,
+			Saved:      true,
+			FollowedAt: search.CreatedAt,
+		}
+	}
+	return records, nil
+}
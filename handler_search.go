@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+type searchRecord struct {
+	ID          uuid.UUID  `json:"id" table:"-"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	FeedName    string     `json:"feed_name"`
+	PublishedAt *time.Time `json:"published_at"`
+	FetchedAt   time.Time  `json:"fetched_at" table:"-"`
+	Highlighted bool       `json:"highlighted"`
+	Rank        float32    `json:"rank"`
+	Snippet     string     `json:"snippet"`
+}
+
+// handlerSearch runs a full-text search over the posts of every feed the
+// user follows. The query uses web search syntax: "quoted phrases", -negation
+// and OR are all supported. Flags go before the query or use two dashes.
+func handlerSearch(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	limit := fs.Int("limit", 10, "number of results to show")
+	feedRef := fs.String("feed", "", "only search posts from this feed (url or name)")
+	args, err := parseQueryFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s [--feed url|name] [--limit n] <query>", cmd.Name)
+	}
+	if *limit < 1 {
+		return fmt.Errorf("result limit must be positive")
+	}
+
+	params := database.SearchPostsForUserParams{
+		Query:     strings.Join(args, " "),
+		UserID:    user.ID,
+		PostLimit: int32(*limit),
+	}
+	if *feedRef != "" {
+		feed, err := lookupFeed(s, *feedRef)
+		if err != nil {
+			return err
+		}
+		params.FeedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+	}
+
+	results, err := s.db.SearchPostsForUser(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("failed to search posts: %w", err)
+	}
+	records := make([]searchRecord, len(results))
+	for i, result := range results {
+		records[i] = searchRecord{
+			ID:          result.ID,
+			Title:       result.Title,
+			URL:         result.Url,
+			FeedName:    result.FeedName,
+			PublishedAt: nullTimePtr(result.PublishedAt),
+			FetchedAt:   result.CreatedAt,
+			Highlighted: result.Highlighted,
+			Rank:        result.Rank,
+			Snippet:     strings.Join(strings.Fields(result.Snippet), " "),
+		}
+	}
+	return s.printRecords(records)
+}
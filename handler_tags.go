@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func handlerTag(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) < 2 {
+		return fmt.Errorf("usage: %s <feed_url|feed_name> <tag>...", cmd.Name)
+	}
+	feed, follow, err := lookupFollow(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	names, err := tagNames(cmd.Args[1:])
+	if err != nil {
+		return err
+	}
+
+	if err := addFollowTags(s, user, follow.ID, names); err != nil {
+		return err
+	}
+
+	fmt.Printf("Tagged feed %s with %s.\n", feed.Name, strings.Join(names, ", "))
+	return nil
+}
+
+func handlerUntag(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) < 2 {
+		return fmt.Errorf("usage: %s <feed_url|feed_name> <tag>...", cmd.Name)
+	}
+	feed, follow, err := lookupFollow(s, user, cmd.Args[0])
+	if err != nil {
+		return err
+	}
+	names, err := tagNames(cmd.Args[1:])
+	if err != nil {
+		return err
+	}
+
+	var removed, missing []string
+	for _, name := range names {
+		n, err := s.db.RemoveFollowTag(context.Background(), database.RemoveFollowTagParams{
+			FeedFollowID: follow.ID,
+			Name:         name,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't untag feed: %w", err)
+		}
+		if n == 0 {
+			missing = append(missing, name)
+		} else {
+			removed = append(removed, name)
+		}
+	}
+	if len(removed) == 0 {
+		return fmt.Errorf("feed %s isn't tagged %s", feed.Name, strings.Join(missing, ", "))
+	}
+	if err := s.db.DeleteUnusedTags(context.Background(), user.ID); err != nil {
+		return fmt.Errorf("couldn't clean up tags: %w", err)
+	}
+
+	if len(missing) > 0 {
+		fmt.Printf("Feed %s isn't tagged %s.\n", feed.Name, strings.Join(missing, ", "))
+	}
+	fmt.Printf("Removed %s from feed %s.\n", strings.Join(removed, ", "), feed.Name)
+	return nil
+}
+
+// addFollowTags tags one of the user's follows, creating tags as needed.
+func addFollowTags(s *state, user database.User, followID uuid.UUID, names []string) error {
+	for _, name := range names {
+		tag, err := s.db.UpsertTag(context.Background(), database.UpsertTagParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			UserID:    user.ID,
+			Name:      name,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't create tag %s: %w", name, err)
+		}
+		_, err = s.db.AddFollowTag(context.Background(), database.AddFollowTagParams{
+			FeedFollowID: followID,
+			TagID:        tag.ID,
+			CreatedAt:    time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't tag feed: %w", err)
+		}
+	}
+	return nil
+}
+
+// lookupFollow finds a feed by URL or name and the user's follow of it.
+func lookupFollow(s *state, user database.User, ref string) (database.Feed, database.FeedFollow, error) {
+	feed, err := lookupFeed(s, ref)
+	if err != nil {
+		return database.Feed{}, database.FeedFollow{}, err
+	}
+	follow, err := s.db.GetFeedFollowForUser(context.Background(), database.GetFeedFollowForUserParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err == sql.ErrNoRows {
+		return database.Feed{}, database.FeedFollow{}, fmt.Errorf("user is not following that feed")
+	}
+	if err != nil {
+		return database.Feed{}, database.FeedFollow{}, fmt.Errorf("couldn't get feed follow: %w", err)
+	}
+	return feed, follow, nil
+}
+
+func tagNames(args []string) ([]string, error) {
+	names := make([]string, 0, len(args))
+	for _, arg := range args {
+		name := strings.ToLower(strings.TrimSpace(arg))
+		if name == "" {
+			return nil, fmt.Errorf("tag names can't be empty")
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// followTags maps each of the user's feed follows to its tag names.
+func followTags(s *state, user database.User) (map[uuid.UUID][]string, error) {
+	rows, err := s.db.GetFollowTagsForUser(context.Background(), user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get tags: %w", err)
+	}
+	tags := make(map[uuid.UUID][]string)
+	for _, row := range rows {
+		tags[row.FeedFollowID] = append(tags[row.FeedFollowID], row.Name)
+	}
+	return tags, nil
+}
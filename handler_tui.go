@@ -0,0 +1,26 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/tui"
+)
+
+func handlerTUI(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	refresh := fs.Duration("refresh", time.Minute, "how often to reload feeds and posts")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: %s [--refresh duration]", cmd.Name)
+	}
+	if *refresh <= 0 {
+		return fmt.Errorf("refresh interval must be positive")
+	}
+	return tui.Run(s.db, user, *refresh, s.output.location)
+}
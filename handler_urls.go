@@ -0,0 +1,99 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/storage"
+	"github.com/Grumpster-Dev/gator/internal/urlnorm"
+)
+
+func (s *state) urlNormalizer() urlnorm.Normalizer {
+	if s.cfg.StripParams == nil {
+		return urlnorm.Default
+	}
+	return urlnorm.Normalizer{StripParams: s.cfg.StripParams}
+}
+
+// normalizeURL returns the normalized form of raw, or raw itself if it
+// doesn't parse.
+func (s *state) normalizeURL(raw string) string {
+	normalized, err := s.urlNormalizer().Normalize(raw)
+	if err != nil {
+		return raw
+	}
+	return normalized
+}
+
+// handlerNormalizeURLs rewrites feed and post URLs stored before
+// normalization was applied at ingestion. Rows whose normalized URL is
+// already taken are left alone and reported.
+func handlerNormalizeURLs(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "report changes without writing them")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil || len(args) != 0 {
+		return fmt.Errorf("usage: %s [--dry-run]", cmd.Name)
+	}
+	ctx := context.Background()
+
+	feeds, err := s.db.GetFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get feeds: %w", err)
+	}
+	var feedsChanged, feedsSkipped int
+	for _, feed := range feeds {
+		normalized := s.normalizeURL(feed.Url)
+		if normalized == feed.Url {
+			continue
+		}
+		if !*dryRun {
+			err := s.db.UpdateFeedURL(ctx, database.UpdateFeedURLParams{ID: feed.ID, Url: normalized})
+			if storage.IsUniqueViolation(err) {
+				fmt.Printf("skipped feed %s: %s is already used by another feed\n", feed.Name, normalized)
+				feedsSkipped++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update feed %s: %w", feed.Name, err)
+			}
+		}
+		fmt.Printf("feed %s: %s -> %s\n", feed.Name, feed.Url, normalized)
+		feedsChanged++
+	}
+
+	posts, err := s.db.ListPostURLs(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get posts: %w", err)
+	}
+	var postsChanged, postsSkipped int
+	for _, post := range posts {
+		normalized := s.normalizeURL(post.Url)
+		if normalized == post.Url {
+			continue
+		}
+		if !*dryRun {
+			err := s.db.UpdatePostURL(ctx, database.UpdatePostURLParams{ID: post.ID, Url: normalized})
+			if storage.IsUniqueViolation(err) {
+				postsSkipped++
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("failed to update post %s: %w", post.ID, err)
+			}
+		}
+		postsChanged++
+	}
+
+	verb := "Updated"
+	if *dryRun {
+		verb = "Would update"
+	}
+	fmt.Printf("%s %d feeds and %d posts.\n", verb, feedsChanged, postsChanged)
+	if feedsSkipped > 0 || postsSkipped > 0 {
+		fmt.Printf("Skipped %d feeds and %d posts whose normalized URL already exists.\n", feedsSkipped, postsSkipped)
+	}
+	return nil
+}
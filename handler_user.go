@@ -2,12 +2,23 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"flag"
 	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
 	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/htmltext"
+	"github.com/Grumpster-Dev/gator/internal/storage"
 	"github.com/google/uuid"
 )
 
@@ -17,14 +28,28 @@ func handlerLogin(s *state, cmd command) error {
 	}
 	name := cmd.Args[0]
 	// Attempt to get the user from the database
-	_, err := s.db.GetUser(context.Background(), name)
+	user, err := s.db.GetUser(context.Background(), name)
 	if err != nil {
 		return fmt.Errorf("couldn't find user: %w", err)
 	}
+	if user.DeactivatedAt.Valid {
+		return fmt.Errorf("user %s is deactivated", name)
+	}
 
-	err = s.cfg.SetUser(name) // Note: no `:=` here because 'err' is already declared
-	if err != nil {
-		return fmt.Errorf("failed to update config: %w", err)
+	if user.PasswordHash.Valid {
+		password, err := readPassword("Password: ")
+		if err != nil {
+			return err
+		}
+		if err := checkPassword(user.PasswordHash.String, password); err != nil {
+			return err
+		}
+	} else if err := redeemPasswordReset(s, user); err != nil {
+		return err
+	}
+
+	if err := startSession(s, user); err != nil {
+		return err
 	}
 
 	fmt.Printf("User %s logged in successfully.\n", name)
@@ -47,58 +72,100 @@ func handlerRegister(s *state, cmd command) error {
 		return fmt.Errorf("couldn't check existing users: %w", err)
 	}
 
+	hash, err := promptNewPassword()
+	if err != nil {
+		return err
+	}
+	userCount, err := s.db.CountUsers(context.Background())
+	if err != nil {
+		return fmt.Errorf("couldn't count users: %w", err)
+	}
+
 	user, err := s.db.CreateUser(
 		context.Background(),
 		database.CreateUserParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now().UTC(),
-			UpdatedAt: time.Now().UTC(),
-			Name:      name,
+			ID:           uuid.New(),
+			CreatedAt:    time.Now().UTC(),
+			UpdatedAt:    time.Now().UTC(),
+			Name:         name,
+			PasswordHash: sql.NullString{String: hash, Valid: true},
+			Role:         newUserRole(userCount),
 		},
 	)
 	if err != nil {
 		return fmt.Errorf("couldn't create user: %w", err)
 	}
-	err = s.cfg.SetUser(user.Name)
-	if err != nil {
-		return fmt.Errorf("failed to update config: %w", err)
+	if err := startSession(s, user); err != nil {
+		return err
 	}
 
 	fmt.Printf("User %s registered successfully with ID %s.\n", user.Name, user.ID)
 	return nil
 }
-func handlerReset(s *state, cmd command) error {
 
-	err := s.db.DeleteUsers(context.Background())
-	if err != nil {
-		return fmt.Errorf("couldn't reset database: %w", err)
+// newUserRole bootstraps a fresh database by making its first account an
+// admin; everyone after that starts as a member.
+func newUserRole(existingUsers int64) string {
+	if existingUsers == 0 {
+		return roleAdmin
 	}
+	return roleMember
+}
 
-	fmt.Println("Database has been reset to its initial state.")
-	return nil
+type userRecord struct {
+	ID        uuid.UUID `json:"id" table:"-"`
+	Name      string    `json:"name"`
+	Current   bool      `json:"current"`
+	Role      string    `json:"role"`
+	Active    bool      `json:"active"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
-func handlerListUsers(s *state, cmd command) error {
+func handlerListUsers(s *state, cmd command, current database.User) error {
 	users, err := s.db.GetUsers(context.Background())
 	if err != nil {
 		return fmt.Errorf("failed to get users: %w", err)
 	}
-	for _, user := range users {
-		if user.Name == s.cfg.CurrentUserName {
-			fmt.Println("* ", user.Name, "(current)")
-		} else {
-			fmt.Println("* ", user.Name)
+	records := make([]userRecord, len(users))
+	for i, user := range users {
+		records[i] = userRecord{
+			ID:        user.ID,
+			Name:      user.Name,
+			Current:   user.ID == current.ID,
+			Role:      user.Role,
+			Active:    !user.DeactivatedAt.Valid,
+			CreatedAt: user.CreatedAt,
 		}
 	}
-	return nil
+	return s.printRecords(records)
 }
 
+// pruneInterval is how often agg --prune applies the retention policy.
+const pruneInterval = time.Hour
+
 func handlerAgg(s *state, cmd command) error {
-	if len(cmd.Args) != 1 {
-		return fmt.Errorf("usage: %s <duration>", cmd.Name)
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	prune := fs.Bool("prune", false, "prune posts over their retention limits every hour")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s <duration> [--prune]", cmd.Name)
+	}
+	if *prune {
+		// Collecting needs no login, but pruning deletes posts, so it takes
+		// the same permission as the prune command.
+		user, err := currentUser(s)
+		if err != nil {
+			return err
+		}
+		if err := checkPermission(user, permAdmin); err != nil {
+			return err
+		}
 	}
 
-	durationStr := cmd.Args[0]
+	durationStr := args[0]
 
 	timeBetweenRequests, err := time.ParseDuration(durationStr)
 	if err != nil {
@@ -107,11 +174,27 @@ func handlerAgg(s *state, cmd command) error {
 
 	ticker := time.NewTicker(timeBetweenRequests)
 	fmt.Printf("Collecting feeds every %v \n", timeBetweenRequests)
+	var lastPrune time.Time
 	for ; ; <-ticker.C {
 		scrapeFeeds(s)
+		if *prune && time.Since(lastPrune) >= pruneInterval {
+			lastPrune = time.Now()
+			autoPrune(s)
+		}
 	}
-	return nil
+}
 
+func autoPrune(s *state) {
+	records, err := prunePosts(s, false, false)
+	if err != nil {
+		fmt.Printf("failed to prune posts: %v\n", err)
+		return
+	}
+	for _, r := range records {
+		if r.Pruned > 0 {
+			fmt.Printf("Pruned %d posts from %s\n", r.Pruned, r.Feed)
+		}
+	}
 }
 
 func handlerAddFeed(s *state, cmd command, user database.User) error {
@@ -119,24 +202,32 @@ func handlerAddFeed(s *state, cmd command, user database.User) error {
 		return fmt.Errorf("usage: %s <feed_name> <feed_url>", cmd.Name)
 	}
 	feedName := cmd.Args[0]
-	feedURL := cmd.Args[1]
+	feedURL := s.normalizeURL(cmd.Args[1])
 
-	feed, err := s.db.CreateFeed(
-		context.Background(),
-		database.CreateFeedParams{
-			ID:        uuid.New(),
-			CreatedAt: time.Now().UTC(),
-			UpdatedAt: time.Now().UTC(),
-			Name:      feedName,
-			Url:       feedURL,
-			UserID:    user.ID, // Replace with actual user ID
-		},
-	)
-	if err != nil {
-		return fmt.Errorf("couldn't create feed: %w", err)
-	}
+	// Feeds are shared, so adding one somebody else added just follows it.
+	feed, err := getFeedByURL(s, cmd.Args[1])
+	if err == nil {
+		fmt.Printf("Feed %s already exists as %s.\n", feed.Url, feed.Name)
+	} else if err != sql.ErrNoRows {
+		return fmt.Errorf("couldn't look up feed: %w", err)
+	} else {
+		feed, err = s.db.CreateFeed(
+			context.Background(),
+			database.CreateFeedParams{
+				ID:        uuid.New(),
+				CreatedAt: time.Now().UTC(),
+				UpdatedAt: time.Now().UTC(),
+				Name:      feedName,
+				Url:       feedURL,
+				AddedBy:   uuid.NullUUID{UUID: user.ID, Valid: true},
+			},
+		)
+		if err != nil {
+			return fmt.Errorf("couldn't create feed: %w", err)
+		}
 
-	fmt.Printf("Feed %s created successfully with ID %s.\n", feed.Name, feed.ID)
+		fmt.Printf("Feed %s created successfully with ID %s.\n", feed.Name, feed.ID)
+	}
 
 	follow, err := s.db.CreateFeedFollow(
 		context.Background(),
@@ -157,26 +248,62 @@ func handlerAddFeed(s *state, cmd command, user database.User) error {
 
 }
 
-func handlerFeeds(s *state, cmd command) error {
-	// Placeholder for future implementation
+type feedRecord struct {
+	ID            uuid.UUID  `json:"id" table:"-"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
 
+// adminFeedRecord is what admins see: who added each feed is account
+// information, so other users don't get that column.
+type adminFeedRecord struct {
+	ID            uuid.UUID  `json:"id" table:"-"`
+	Name          string     `json:"name"`
+	URL           string     `json:"url"`
+	AddedBy       string     `json:"added_by"`
+	CreatedAt     time.Time  `json:"created_at"`
+	LastFetchedAt *time.Time `json:"last_fetched_at"`
+}
+
+func handlerFeeds(s *state, cmd command, user database.User) error {
 	listfeeds, err := s.db.GetFeedsByUserName(context.Background())
 	if err != nil {
 		return fmt.Errorf("failed to get feeds: %w", err)
 	}
-	for _, feed := range listfeeds {
-		fmt.Printf("* %s (%s) by %s\n", feed.Name, feed.Url, feed.UserName)
+	if hasPermission(user, permAdmin) {
+		records := make([]adminFeedRecord, len(listfeeds))
+		for i, feed := range listfeeds {
+			records[i] = adminFeedRecord{
+				ID:            feed.ID,
+				Name:          feed.Name,
+				URL:           feed.Url,
+				AddedBy:       feed.UserName.String,
+				CreatedAt:     feed.CreatedAt,
+				LastFetchedAt: nullTimePtr(feed.LastFetchedAt),
+			}
+		}
+		return s.printRecords(records)
+	}
+	records := make([]feedRecord, len(listfeeds))
+	for i, feed := range listfeeds {
+		records[i] = feedRecord{
+			ID:            feed.ID,
+			Name:          feed.Name,
+			URL:           feed.Url,
+			CreatedAt:     feed.CreatedAt,
+			LastFetchedAt: nullTimePtr(feed.LastFetchedAt),
+		}
 	}
-	return nil
+	return s.printRecords(records)
 }
 
 func handlerFollow(s *state, cmd command, user database.User) error {
 	if len(cmd.Args) != 1 {
 		return fmt.Errorf("usage: %s <url>", cmd.Name)
 	}
-	url := cmd.Args[0]
-
-	feed, err := s.db.GetFeedByURL(context.Background(), url)
+	feed, err := getFeedByURL(s, cmd.Args[0])
 	if err != nil {
 		return fmt.Errorf("couldn't find feed by URL: %w", err)
 	}
@@ -200,14 +327,110 @@ func handlerFollow(s *state, cmd command, user database.User) error {
 	return nil
 }
 
+type followRecord struct {
+	FeedID     uuid.UUID `json:"feed_id" table:"-"`
+	FeedName   string    `json:"feed_name"`
+	FeedURL    string    `json:"feed_url"`
+	Tags       []string  `json:"tags"`
+	Unread     int64     `json:"unread"`
+	Saved      bool      `json:"saved"`
+	FollowedAt time.Time `json:"followed_at"`
+}
+
 func handlerFollowing(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	tagFilter := fs.String("tag", "", "only show feeds with this tag")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 0 {
+		return fmt.Errorf("usage: %s [--tag tag]", cmd.Name)
+	}
+	*tagFilter = strings.ToLower(strings.TrimSpace(*tagFilter))
 
 	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
 	if err != nil {
 		return fmt.Errorf("failed to get feed follows: %w", err)
 	}
+	tags, err := followTags(s, user)
+	if err != nil {
+		return err
+	}
+	counts, err := s.db.GetFeedsWithUnreadCounts(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get unread counts: %w", err)
+	}
+	unread := make(map[uuid.UUID]int64, len(counts))
+	for _, count := range counts {
+		unread[count.ID] = count.UnreadCount
+	}
+
+	records := []followRecord{}
 	for _, follow := range follows {
-		fmt.Printf("* %s \n", follow.FeedName)
+		if *tagFilter != "" && !slices.Contains(tags[follow.ID], *tagFilter) {
+			continue
+		}
+		records = append(records, followRecord{
+			FeedID:     follow.FeedID,
+			FeedName:   follow.FeedName,
+			FeedURL:    follow.FeedUrl,
+			Tags:       tags[follow.ID],
+			Unread:     unread[follow.FeedID],
+			FollowedAt: follow.CreatedAt,
+		})
+	}
+	if *tagFilter == "" {
+		searches, err := savedSearchRecords(s, user)
+		if err != nil {
+			return err
+		}
+		records = append(records, searches...)
+	}
+	if *tagFilter != "" || len(tags) == 0 || s.output.format != outputTable || s.output.template != nil {
+		return s.printRecords(records)
+	}
+
+	// Group the table by tag; a feed with several tags shows up under each,
+	// and saved searches come last.
+	groups := make(map[string][]followRecord)
+	var untagged, searches []followRecord
+	for _, record := range records {
+		switch {
+		case record.Saved:
+			searches = append(searches, record)
+		case len(record.Tags) == 0:
+			untagged = append(untagged, record)
+		}
+		if record.Saved {
+			continue
+		}
+		for _, tag := range record.Tags {
+			groups[tag] = append(groups[tag], record)
+		}
+	}
+	type group struct {
+		title   string
+		records []followRecord
+	}
+	var ordered []group
+	for _, name := range slices.Sorted(maps.Keys(groups)) {
+		ordered = append(ordered, group{"[" + name + "]", groups[name]})
+	}
+	if len(untagged) > 0 {
+		ordered = append(ordered, group{"[untagged]", untagged})
+	}
+	if len(searches) > 0 {
+		ordered = append(ordered, group{"[saved searches]", searches})
+	}
+	for i, g := range ordered {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(g.title)
+		if err := s.printRecords(g.records); err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -217,9 +440,7 @@ func handlerUnfollow(s *state, cmd command, user database.User) error {
 		return fmt.Errorf("usage: %s <url>", cmd.Name)
 	}
 
-	feedURL := cmd.Args[0]
-
-	feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+	feed, err := getFeedByURL(s, cmd.Args[0])
 	if err != nil {
 		return fmt.Errorf("couldn't find feed by URL: %w", err)
 	}
@@ -252,16 +473,35 @@ func handlerUnfollow(s *state, cmd command, user database.User) error {
 	if err != nil {
 		return fmt.Errorf("couldn't unfollow feed: %w", err)
 	}
+	if err := s.db.DeleteUnusedTags(context.Background(), user.ID); err != nil {
+		return fmt.Errorf("couldn't clean up tags: %w", err)
+	}
 
 	fmt.Printf("User %s unfollowed feed %s.\n", user.Name, feed.Name)
+	return collectUnusedFeeds(s)
+}
+
+// collectUnusedFeeds deletes feeds that nobody follows any more, along with
+// their posts, unless someone starred one of those posts.
+func collectUnusedFeeds(s *state) error {
+	n, err := s.db.DeleteUnusedFeeds(context.Background())
+	if err != nil {
+		return fmt.Errorf("couldn't remove unused feeds: %w", err)
+	}
+	if n > 0 {
+		fmt.Printf("Removed %d feeds that no one follows.\n", n)
+	}
 	return nil
 }
 
 func middlewareLoggedIn(handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
 	return func(s *state, cmd command) error {
-		user, err := s.db.GetUser(context.Background(), s.cfg.CurrentUserName)
+		user, err := currentUser(s)
 		if err != nil {
-			return fmt.Errorf("couldn't find current user: %w", err)
+			return err
+		}
+		if err := loadPreferences(s, user); err != nil {
+			return err
 		}
 		return handler(s, cmd, user)
 	}
@@ -297,54 +537,343 @@ func scrapeFeeds(s *state) {
 			// Parsing failed, no date available
 			publishedAt = sql.NullTime{Valid: false}
 		}
+		author := item.Author
+		if author == "" {
+			author = item.Creator
+		}
+		categories := item.Categories
+		if categories == nil {
+			categories = []string{}
+		}
+		postID := uuid.New()
+		postURL := s.normalizeURL(item.Link)
+		fingerprint := postFingerprint(item.Title, item.Description)
+		clusterID, err := duplicateCluster(s, postURL, fingerprint)
+		if err != nil {
+			fmt.Printf("failed to check for duplicate posts: %v\n", err)
+			continue
+		}
+		if clusterID == uuid.Nil {
+			clusterID = postID
+		}
+
 		postParams := database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   time.Now().UTC(),
-			UpdatedAt:   time.Now().UTC(),
-			Title:       item.Title,
-			Url:         item.Link,
-			Description: sql.NullString{String: item.Description, Valid: true},
-			PublishedAt: publishedAt,
-			FeedID:      feed.ID,
+			ID:           postID,
+			CreatedAt:    time.Now().UTC(),
+			UpdatedAt:    time.Now().UTC(),
+			Title:        item.Title,
+			Url:          postURL,
+			Description:  sql.NullString{String: item.Description, Valid: true},
+			PublishedAt:  publishedAt,
+			FeedID:       feed.ID,
+			Author:       sql.NullString{String: author, Valid: author != ""},
+			Content:      sql.NullString{String: item.Content, Valid: item.Content != ""},
+			Categories:   categories,
+			CanonicalUrl: sql.NullString{String: postURL, Valid: true},
+			Fingerprint:  sql.NullString{String: fingerprint, Valid: fingerprint != ""},
+			ClusterID:    clusterID,
 		}
 		_, err = s.db.CreatePost(context.Background(), postParams)
-		if err != nil {
-			if err.Error() == "pq: duplicate key value violates unique constraint \"posts_url_key\"" {
-				continue
-			} else {
-				fmt.Printf("failed to create post: %v\n", err)
-			}
-		} else {
-			fmt.Printf("DEBUG: created post %s for feed %s\n", item.Title, feed.ID)
+		if err != nil && !storage.IsUniqueViolation(err) {
+			// A unique violation means the post is already stored for
+			// this feed.
+			fmt.Printf("failed to create post: %v\n", err)
 		}
 	}
 }
 
-func handlerBrowsePosts(s *state, cmd command, user database.User) error {
+// duplicateWindow bounds how far back ingestion looks for another copy of
+// the same story.
+const duplicateWindow = 14 * 24 * time.Hour
+
+// duplicateCluster returns the cluster of a recent post with the same
+// canonical URL or content fingerprint, or uuid.Nil if the story is new.
+func duplicateCluster(s *state, canonicalURL, fingerprint string) (uuid.UUID, error) {
+	dup, err := s.db.FindDuplicatePost(context.Background(), database.FindDuplicatePostParams{
+		CanonicalUrl: sql.NullString{String: canonicalURL, Valid: true},
+		Fingerprint:  sql.NullString{String: fingerprint, Valid: fingerprint != ""},
+		Since:        time.Now().UTC().Add(-duplicateWindow),
+	})
+	if err == sql.ErrNoRows {
+		return uuid.Nil, nil
+	}
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return dup.ClusterID, nil
+}
+
+// minFingerprintSummary is how much normalized description text a post
+// needs for a fingerprint. Titles alone ("Weekly update", "Episode 12")
+// repeat across unrelated feeds, so shorter posts are only matched on
+// their canonical URL.
+const minFingerprintSummary = 80
+
+// postFingerprint hashes a post's normalized title and the start of its
+// description, so that syndicated copies of a story with cosmetic
+// differences (case, punctuation, markup) get the same fingerprint.
+func postFingerprint(title, description string) string {
+	normalize := func(text string) string {
+		text = strings.ToLower(htmltext.PlainText(text))
+		text = strings.Map(func(r rune) rune {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				return r
+			}
+			return ' '
+		}, text)
+		return strings.Join(strings.Fields(text), " ")
+	}
+	title = normalize(title)
+	if title == "" {
+		return ""
+	}
+	summary := []rune(normalize(description))
+	if len(summary) < minFingerprintSummary {
+		return ""
+	}
+	if len(summary) > 300 {
+		summary = summary[:300]
+	}
+	sum := sha256.Sum256([]byte(title + "\n" + string(summary)))
+	return hex.EncodeToString(sum[:])
+}
+
+type postRecord struct {
+	ID          uuid.UUID  `json:"id" table:"-"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Author      string     `json:"author"`
+	FeedID      uuid.UUID  `json:"feed_id" table:"-"`
+	FeedName    string     `json:"feed_name"`
+	FeedURL     string     `json:"feed_url" table:"-"`
+	PublishedAt *time.Time `json:"published_at"`
+	FetchedAt   time.Time  `json:"fetched_at" table:"-"`
+	Highlighted bool       `json:"highlighted"`
+	AlsoIn      []string   `json:"also_in"`
+	Description string     `json:"description" table:"-"`
+	Cursor      string     `json:"cursor" table:"-"`
+}
 
-	var postLimit int32 = 2
-	if len(cmd.Args) == 1 {
-		n, err := strconv.Atoi(cmd.Args[0])
+func handlerBrowsePosts(s *state, cmd command, user database.User) error {
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	limit := fs.Int("limit", s.prefInt(prefBrowseLimit), "number of posts to show")
+	page := fs.Int("page", 1, "page number, counted in pages of --limit posts")
+	after := fs.String("after", "", "cursor printed by a previous browse")
+	sortBy := fs.String("sort", s.pref(prefSort), "sort order: published or fetched")
+	feedRef := fs.String("feed", "", "only show posts from this feed (url or name)")
+	since := fs.String("since", "", "only show posts newer than a duration (24h) or date")
+	until := fs.String("until", "", "only show posts older than a duration (24h) or date")
+	author := fs.String("author", "", "only show posts whose author contains this text")
+	tag := fs.String("tag", "", "only show posts from feeds with this tag")
+	saved := fs.String("saved", "", "start from a saved search; other flags override it")
+	duplicates := fs.Bool("duplicates", false, "show every copy of a story instead of grouping them")
+	descriptions := fs.Bool("descriptions", s.prefBool(prefShowDescriptions), "show post descriptions in the table")
+	args, err := parseFlags(fs, cmd.Args)
+	if err != nil {
+		return err
+	}
+	if len(args) > 1 {
+		return fmt.Errorf("usage: %s [limit] [--page n | --after cursor] [--sort published|fetched] [--feed url|name] [--since t] [--until t] [--author name] [--tag tag] [--saved name] [--duplicates] [--descriptions]", cmd.Name)
+	}
+	if len(args) == 1 {
+		n, err := strconv.Atoi(args[0])
 		if err != nil {
 			return fmt.Errorf("invalid post limit: %w", err)
 		}
-		postLimit = int32(n)
+		*limit = n
+	}
+	if *limit < 1 {
+		return fmt.Errorf("post limit must be positive")
+	}
+	if *page < 1 {
+		return fmt.Errorf("page must be positive")
+	}
+	if *sortBy != "published" && *sortBy != "fetched" {
+		return fmt.Errorf("invalid sort %q: use published or fetched", *sortBy)
+	}
+	if *after != "" && *page != 1 {
+		return fmt.Errorf("--page and --after can't be combined")
 	}
 
-	postParams := database.GetPostsByUserParams{
-		UserID: user.ID,
-		Limit:  postLimit,
+	params := database.BrowsePostsForUserParams{
+		SortBy:             *sortBy,
+		UserID:             user.ID,
+		CollapseDuplicates: !*duplicates,
+		PostLimit:          int32(*limit),
+		PostOffset:         int32((*page - 1) * *limit),
+	}
+	if *saved != "" {
+		search, err := s.db.GetSavedSearch(context.Background(), database.GetSavedSearchParams{
+			UserID: user.ID,
+			Name:   *saved,
+		})
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("no saved search named %q", *saved)
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't get saved search: %w", err)
+		}
+		params.Query = search.Query
+		params.FeedIds = search.FeedIds
+		params.Tags = search.Tags
+		params.Author = search.Author
+		params.Since = savedSearchSince(search)
+	}
+	if *feedRef != "" {
+		feed, err := lookupFeed(s, *feedRef)
+		if err != nil {
+			return err
+		}
+		params.FeedIds = []uuid.UUID{feed.ID}
+	}
+	if *author != "" {
+		params.Author = sql.NullString{String: *author, Valid: true}
+	}
+	if *tag != "" {
+		params.Tags = []string{strings.ToLower(strings.TrimSpace(*tag))}
+	}
+	if *since != "" {
+		t, err := parseTimeArg(*since)
+		if err != nil {
+			return err
+		}
+		params.Since = sql.NullTime{Time: t, Valid: true}
+	}
+	if *until != "" {
+		t, err := parseTimeArg(*until)
+		if err != nil {
+			return err
+		}
+		params.Until = sql.NullTime{Time: t, Valid: true}
+	}
+	if *after != "" {
+		at, id, err := decodeCursor(*after)
+		if err != nil {
+			return err
+		}
+		params.AfterAt = sql.NullTime{Time: at, Valid: true}
+		params.AfterID = uuid.NullUUID{UUID: id, Valid: true}
 	}
 
-	posts, err := s.db.GetPostsByUser(context.Background(), postParams)
+	posts, err := s.db.BrowsePostsForUser(context.Background(), params)
 	if err != nil {
 		return fmt.Errorf("failed to get posts: %w", err)
 	}
 
-	fmt.Printf("DEBUG: got %d posts for user %s\n", len(posts), user.Name)
-
-	for _, post := range posts {
-		fmt.Printf("* %s (%s) from feed ID %s\n", post.Title, post.Url, post.FeedID.String())
+	records := make([]postRecord, len(posts))
+	for i, post := range posts {
+		records[i] = postRecord{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Author:      post.Author.String,
+			FeedID:      post.FeedID,
+			FeedName:    post.FeedName,
+			FeedURL:     post.FeedUrl,
+			PublishedAt: nullTimePtr(post.PublishedAt),
+			FetchedAt:   post.CreatedAt,
+			Highlighted: post.Highlighted,
+			AlsoIn:      post.AlsoIn,
+			Description: htmltext.Summary(post.Description.String, 200),
+			Cursor:      encodeCursor(post.SortAt, post.ID),
+		}
+	}
+	if *descriptions {
+		s.output.extraColumns = append(s.output.extraColumns, "description")
+	}
+	if err := s.printRecords(records); err != nil {
+		return err
+	}
+	if len(records) == *limit && s.output.format == outputTable && s.output.template == nil {
+		fmt.Printf("More: %s\n", nextPageCommand(os.Args, records[len(records)-1].Cursor))
 	}
 	return nil
 }
+
+// lookupFeed finds a feed by its URL, falling back to its name.
+func lookupFeed(s *state, ref string) (database.Feed, error) {
+	feed, err := getFeedByURL(s, ref)
+	if err == nil {
+		return feed, nil
+	}
+	if err != sql.ErrNoRows {
+		return database.Feed{}, fmt.Errorf("couldn't look up feed: %w", err)
+	}
+	feed, err = s.db.GetFeed(context.Background(), ref)
+	if err == sql.ErrNoRows {
+		return database.Feed{}, fmt.Errorf("no feed with url or name %q", ref)
+	}
+	if err != nil {
+		return database.Feed{}, fmt.Errorf("couldn't look up feed: %w", err)
+	}
+	return feed, nil
+}
+
+// getFeedByURL looks a feed up by its URL as given, then by its normalized
+// form, so rows stored before normalization still match.
+func getFeedByURL(s *state, feedURL string) (database.Feed, error) {
+	feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+	if err == sql.ErrNoRows {
+		if normalized := s.normalizeURL(feedURL); normalized != feedURL {
+			return s.db.GetFeedByURL(context.Background(), normalized)
+		}
+	}
+	return feed, err
+}
+
+// nextPageCommand rewrites a browse command line to fetch the page after
+// cursor: any --page or --after is replaced, and every other flag is kept
+// so that the next page uses the same sort and filters.
+func nextPageCommand(args []string, cursor string) string {
+	var words []string
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
+		if i == 0 {
+			words = append(words, filepath.Base(arg))
+			continue
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(arg, "-"), "=")
+		if strings.HasPrefix(arg, "-") && (name == "page" || name == "after") {
+			if !hasValue {
+				i++
+			}
+			continue
+		}
+		words = append(words, shellQuote(arg))
+	}
+	words = append(words, "--after", cursor)
+	return strings.Join(words, " ")
+}
+
+// shellQuote quotes arg for a POSIX shell when it isn't safe to paste as is.
+func shellQuote(arg string) string {
+	safe := func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_./:=,@%+", r)
+	}
+	if arg != "" && strings.IndexFunc(arg, func(r rune) bool { return !safe(r) }) < 0 {
+		return arg
+	}
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+// encodeCursor turns the sort key of the last post on a page into an opaque
+// keyset cursor for `browse --after`.
+func encodeCursor(at time.Time, id uuid.UUID) string {
+	return fmt.Sprintf("%d_%s", at.UnixMicro(), id)
+}
+
+func decodeCursor(cursor string) (time.Time, uuid.UUID, error) {
+	micros, idStr, ok := strings.Cut(cursor, "_")
+	if !ok {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	n, err := strconv.ParseInt(micros, 10, 64)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return time.Time{}, uuid.UUID{}, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return time.UnixMicro(n).UTC(), id, nil
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	at := time.Date(2026, 10, 12, 10, 0, 0, 123456000, time.UTC)
+	id := uuid.MustParse("f887ca42-aa53-4afe-b25b-97b1140ce362")
+
+	gotAt, gotID, err := decodeCursor(encodeCursor(at, id))
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if !gotAt.Equal(at) || gotID != id {
+		t.Errorf("round trip = %v, %v; want %v, %v", gotAt, gotID, at, id)
+	}
+}
+
+func TestDecodeCursorInvalid(t *testing.T) {
+	for _, cursor := range []string{
+		"",
+		"1791799200000000",
+		"abc_f887ca42-aa53-4afe-b25b-97b1140ce362",
+		"1791799200000000_not-a-uuid",
+	} {
+		if _, _, err := decodeCursor(cursor); err == nil {
+			t.Errorf("decodeCursor(%q) succeeded, want an error", cursor)
+		}
+	}
+}
+
+func TestNextPageCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{
+			args: []string{"/usr/local/bin/gator", "browse"},
+			want: "gator browse --after C",
+		},
+		{
+			args: []string{"gator", "browse", "5", "--sort", "fetched", "--tag", "news", "--page", "3"},
+			want: "gator browse 5 --sort fetched --tag news --after C",
+		},
+		{
+			args: []string{"gator", "--profile", "work", "browse", "--after=OLD", "-feed", "Go blog", "--duplicates"},
+			want: "gator --profile work browse -feed 'Go blog' --duplicates --after C",
+		},
+		{
+			args: []string{"gator", "browse", "--after", "OLD", "--author", "O'Brien"},
+			want: `gator browse --author 'O'\''Brien' --after C`,
+		},
+	}
+	for _, tt := range tests {
+		if got := nextPageCommand(tt.args, "C"); got != tt.want {
+			t.Errorf("nextPageCommand(%q) = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestPostFingerprint(t *testing.T) {
+	body := "The Go team is happy to announce a new release, with faster builds, better generics and a new garbage collector."
+
+	fp := postFingerprint("Go 1.30 is released", body)
+	if fp == "" {
+		t.Fatal("postFingerprint returned no fingerprint for a post with a description")
+	}
+	if got := postFingerprint("  GO 1.30 is Released!", "<p>"+strings.ToUpper(body)+"</p>"); got != fp {
+		t.Errorf("cosmetic differences changed the fingerprint: %s != %s", got, fp)
+	}
+	long := strings.Repeat(body+" ", 3)
+	if postFingerprint("Go 1.30 is released", long) != postFingerprint("Go 1.30 is released", long+"Read on.") {
+		t.Error("text past the summary length changed the fingerprint")
+	}
+	if got := postFingerprint("Go 1.31 is released", body); got == fp {
+		t.Error("a different title got the same fingerprint")
+	}
+
+	for _, tt := range []struct{ title, description string }{
+		{"Weekly update", ""},
+		{"Episode 12", "Listen now."},
+		{"", body},
+		{"<br>", body},
+	} {
+		if got := postFingerprint(tt.title, tt.description); got != "" {
+			t.Errorf("postFingerprint(%q, %q) = %q, want none", tt.title, tt.description, got)
+		}
+	}
+}
+
+func TestFeedsHidesAddedByFromNonAdmins(t *testing.T) {
+	s := newTestState(t)
+	admin := createTestUser(t, s, "admin", roleAdmin, sql.NullString{String: "x", Valid: true})
+	reader := createTestUser(t, s, "reader", roleReadOnly, sql.NullString{String: "x", Valid: true})
+	createTestFeed(t, s, "news", admin)
+
+	for _, tc := range []struct {
+		user        database.User
+		wantAddedBy bool
+	}{
+		{admin, true},
+		{reader, false},
+	} {
+		for _, format := range []string{outputTable, outputJSON} {
+			s.output.format = format
+			out := captureStdout(t, func() error {
+				return handlerFeeds(s, command{Name: "feeds"}, tc.user)
+			})
+			if !strings.Contains(out, "news") {
+				t.Errorf("%s/%s: feed missing from output:\n%s", tc.user.Name, format, out)
+			}
+			if got := strings.Contains(strings.ToLower(out), "added_by"); got != tc.wantAddedBy {
+				t.Errorf("%s/%s: added_by shown = %v, want %v:\n%s", tc.user.Name, format, got, tc.wantAddedBy, out)
+			}
+		}
+	}
+}
+
+func TestAggPruneNeedsAdmin(t *testing.T) {
+	s := newTestState(t)
+	cmd := command{Name: "agg", Args: []string{"1h", "--prune"}}
+	if err := handlerAgg(s, cmd); err == nil || !strings.Contains(err.Error(), "not logged in") {
+		t.Fatalf("agg --prune logged out: got %v, want a login error", err)
+	}
+	member := createTestUser(t, s, "member", roleMember, sql.NullString{String: "x", Valid: true})
+	if err := startSession(s, member); err != nil {
+		t.Fatal(err)
+	}
+	if err := handlerAgg(s, cmd); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Fatalf("agg --prune as a member: got %v, want permission denied", err)
+	}
+}
+
+// TestAddFeedMatchesStoredURL checks that addfeed and import follow a feed
+// stored before URL normalization instead of adding a second copy.
+func TestAddFeedMatchesStoredURL(t *testing.T) {
+	s := newTestState(t)
+	user := createTestUser(t, s, "ada", roleMember, sql.NullString{String: "x", Valid: true})
+	const rawURL = "https://blog.example.com/feed?utm_source=rss"
+	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Name:      "blog",
+		Url:       rawURL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	captureStdout(t, func() error {
+		return handlerAddFeed(s, command{Name: "addfeed", Args: []string{"blog", rawURL}}, user)
+	})
+	opmlPath := filepath.Join(t.TempDir(), "feeds.opml")
+	opml := `<opml version="2.0"><body><outline text="blog" xmlUrl="` + rawURL + `"/></body></opml>`
+	if err := os.WriteFile(opmlPath, []byte(opml), 0600); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() error {
+		return handlerImport(s, command{Name: "import", Args: []string{opmlPath}}, user)
+	})
+
+	feeds, err := s.db.GetFeeds(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(feeds) != 1 || feeds[0].ID != feed.ID {
+		t.Fatalf("got %d feeds, want only the stored one", len(feeds))
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(follows) != 1 {
+		t.Fatalf("got %d follows, want 1", len(follows))
+	}
+}
+
+func TestUnfollowDeletesUnusedTags(t *testing.T) {
+	s := newTestState(t)
+	user := createTestUser(t, s, "ada", roleMember, sql.NullString{String: "x", Valid: true})
+	for _, name := range []string{"go", "rust"} {
+		feed := createTestFeed(t, s, name, user)
+		_, err := s.db.CreateFeedFollow(context.Background(), database.CreateFeedFollowParams{
+			ID:        uuid.New(),
+			CreatedAt: time.Now().UTC(),
+			UpdatedAt: time.Now().UTC(),
+			UserID:    user.ID,
+			FeedID:    feed.ID,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		captureStdout(t, func() error {
+			return handlerTag(s, command{Name: "tag", Args: []string{name, name}}, user)
+		})
+	}
+
+	captureStdout(t, func() error {
+		return handlerUnfollow(s, command{Name: "unfollow", Args: []string{"https://go.example.com/feed"}}, user)
+	})
+	tags, err := s.db.ListTags(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 1 || tags[0].Name != "rust" {
+		t.Fatalf("tags after unfollowing go: %v, want only rust", tags)
+	}
+}
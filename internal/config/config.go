@@ -2,65 +2,353 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 )
 
 type Config struct {
-	DBURL           string `json:"db_url"`
-	CurrentUserName string `json:"current_user_name"`
+	DBURL        string      `json:"db_url"`
+	SessionToken string      `json:"session_token,omitempty"`
+	SMTP         *SMTPConfig `json:"smtp,omitempty"`
+	// Sessions holds the session token of each user logged in with this
+	// profile, so that GATOR_USER can switch between them.
+	Sessions map[string]string `json:"sessions,omitempty"`
+	// StripParams lists the query parameters removed from feed and post
+	// URLs. Null means urlnorm.DefaultStripParams; [] keeps everything.
+	StripParams []string `json:"strip_params"`
+	// Retention is the default post retention policy; feeds can override
+	// it. Nil keeps posts forever.
+	Retention *RetentionConfig `json:"retention,omitempty"`
+
+	path     string
+	profile  string
+	user     string
+	created  bool
+	insecure bool
+}
+
+// SMTPConfig holds the mail server used to send digests.
+type SMTPConfig struct {
+	Host     string   `json:"host"`
+	Port     int      `json:"port"`
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
 }
 
+// RetentionConfig limits how many posts prune keeps per feed. Zero means
+// no limit.
+type RetentionConfig struct {
+	MaxAgeDays int `json:"max_age_days,omitempty"`
+	MaxPosts   int `json:"max_posts,omitempty"`
+}
+
+// Options select which config file and profile Read loads. Empty fields
+// fall back to the GATOR_CONFIG and GATOR_PROFILE environment variables.
+type Options struct {
+	Path    string
+	Profile string
+	// CreateProfile treats an unknown profile as empty instead of an
+	// error; it is written out on the first change.
+	CreateProfile bool
+}
+
+const (
+	EnvConfig       = "GATOR_CONFIG"
+	EnvProfile      = "GATOR_PROFILE"
+	EnvDBURL        = "GATOR_DB_URL"
+	EnvUser         = "GATOR_USER"
+	EnvSessionToken = "GATOR_SESSION_TOKEN"
+)
+
+// DefaultProfile names the settings at the top level of the config file.
+const DefaultProfile = "default"
+
 const configFileName = ".gatorconfig.json"
 
-func getConfigFilePath() (string, error) {
-	home, err := os.UserHomeDir()
+// file is the on-disk layout: the default profile at the top level and
+// named profiles under "profiles".
+type file struct {
+	Config
+	Profiles map[string]*Config `json:"profiles,omitempty"`
+}
+
+func (f *file) section(profile string, create bool) (*Config, error) {
+	if profile == DefaultProfile {
+		return &f.Config, nil
+	}
+	if section, ok := f.Profiles[profile]; ok {
+		return section, nil
+	}
+	if !create {
+		return nil, fmt.Errorf("no profile %q in config", profile)
+	}
+	if f.Profiles == nil {
+		f.Profiles = make(map[string]*Config)
+	}
+	f.Profiles[profile] = &Config{}
+	return f.Profiles[profile], nil
+}
+
+// getConfigFilePath picks the config file: an explicit path, then
+// GATOR_CONFIG, then an existing XDG or legacy home-directory file, and
+// finally the XDG location for a new one.
+func getConfigFilePath(explicit string) (string, error) {
+	if explicit != "" {
+		return explicit, nil
+	}
+	if path := os.Getenv(EnvConfig); path != "" {
+		return path, nil
+	}
+
+	configDir, err := os.UserConfigDir()
 	if err != nil {
 		return "", err
 	}
-	return filepath.Join(home, configFileName), nil
+	xdgPath := filepath.Join(configDir, "gator", "config.json")
+	if _, err := os.Stat(xdgPath); err == nil {
+		return xdgPath, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		legacyPath := filepath.Join(home, configFileName)
+		if _, err := os.Stat(legacyPath); err == nil {
+			return legacyPath, nil
+		}
+	}
+	return xdgPath, nil
 }
 
-func write(cfg Config) error {
-	path, err := getConfigFilePath()
+func readFile(path string) (file, error) {
+	var f file
+	data, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return f, err
+	}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return f, fmt.Errorf("invalid config %s: %w", path, err)
 	}
+	return f, nil
+}
 
-	data, err := json.MarshalIndent(cfg, "", "  ")
+// writeFile replaces the config file atomically: the new contents go to a
+// temporary file in the same directory, which is then renamed over path.
+func writeFile(path string, f file) error {
+	data, err := json.MarshalIndent(f, "", "  ")
 	if err != nil {
 		return err
 	}
-	err = os.WriteFile(path, data, 0644)
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp*")
 	if err != nil {
 		return err
 	}
-	return nil
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
 }
 
-func Read() (Config, error) {
-	path, err := getConfigFilePath()
+// Read loads the selected profile, creating an empty config file on first
+// run, and applies the GATOR_DB_URL, GATOR_USER and GATOR_SESSION_TOKEN
+// overrides. GATOR_USER only picks among the sessions already saved in the
+// profile; it never logs anyone in by itself.
+func Read(opts Options) (Config, error) {
+	path, err := getConfigFilePath(opts.Path)
 	if err != nil {
 		return Config{}, err
 	}
+	profile := opts.Profile
+	if profile == "" {
+		profile = os.Getenv(EnvProfile)
+	}
+	if profile == "" {
+		profile = DefaultProfile
+	}
 
-	data, err := os.ReadFile(path)
-	if err != nil {
+	f, err := readFile(path)
+	created := false
+	if errors.Is(err, os.ErrNotExist) {
+		if err := createFile(path); err != nil {
+			return Config{}, fmt.Errorf("couldn't create config: %w", err)
+		}
+		created = true
+	} else if err != nil {
 		return Config{}, err
 	}
+	insecure := false
+	if info, err := os.Stat(path); err == nil {
+		insecure = tooPermissive(info)
+	}
 
-	var cfg Config
-	err = json.Unmarshal(data, &cfg)
+	section, err := f.section(profile, opts.CreateProfile)
 	if err != nil {
-		return Config{}, err
+		return Config{}, fmt.Errorf("%w (in %s)", err, path)
+	}
+	cfg := *section
+	cfg.path = path
+	cfg.profile = profile
+	cfg.created = created
+	cfg.insecure = insecure
+
+	if dbURL := os.Getenv(EnvDBURL); dbURL != "" {
+		cfg.DBURL = dbURL
+	}
+	if user := os.Getenv(EnvUser); user != "" {
+		cfg.user = user
+		cfg.SessionToken = cfg.Sessions[user]
+	}
+	if token := os.Getenv(EnvSessionToken); token != "" {
+		cfg.SessionToken = token
 	}
 	return cfg, nil
 }
 
-func (c *Config) SetUser(userName string) error {
-	c.CurrentUserName = userName
-	if err := write(*c); err != nil {
+// User returns the user GATOR_USER asks for, or "" if it isn't set.
+func (c *Config) User() string {
+	return c.user
+}
+
+// Path returns the file the config was read from.
+func (c *Config) Path() string {
+	return c.path
+}
+
+// Profile returns the name of the profile in use.
+func (c *Config) Profile() string {
+	return c.profile
+}
+
+// Created reports whether Read had to create the config file.
+func (c *Config) Created() bool {
+	return c.created
+}
+
+// Insecure reports whether the config file is readable by other users.
+func (c *Config) Insecure() bool {
+	return c.insecure
+}
+
+// createFile writes an empty config to path unless another process got
+// there first.
+func createFile(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
 		return err
 	}
-	return nil
+	unlock, err := lockFile(path)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	return writeFile(path, file{})
+}
+
+// update applies fn to this profile both in memory and in the file on
+// disk. Only the change is written, so environment overrides and edits
+// made by other processes since Read are kept. The file stays locked
+// from the read to the write so concurrent updates can't interleave.
+func (c *Config) update(fn func(*Config)) error {
+	if err := os.MkdirAll(filepath.Dir(c.path), 0700); err != nil {
+		return err
+	}
+	unlock, err := lockFile(c.path)
+	if err != nil {
+		return fmt.Errorf("couldn't lock config: %w", err)
+	}
+	defer unlock()
+
+	f, err := readFile(c.path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	section, err := f.section(c.profile, true)
+	if err != nil {
+		return err
+	}
+	fn(section)
+	fn(c)
+	return writeFile(c.path, f)
+}
+
+// SetSession saves the token of user's new session. It becomes the
+// profile's current session unless GATOR_USER is set, in which case only
+// that user's entry changes.
+func (c *Config) SetSession(user, token string) error {
+	override := c.user != ""
+	return c.update(func(cfg *Config) {
+		old := cfg.Sessions[user]
+		if cfg.Sessions == nil {
+			cfg.Sessions = make(map[string]string)
+		}
+		cfg.Sessions[user] = token
+		// This process acts as user either way; the file's current session
+		// only moves to user if it was theirs already.
+		if !override || cfg == c || (old != "" && cfg.SessionToken == old) {
+			cfg.SessionToken = token
+		}
+	})
+}
+
+// ClearSession forgets the current session token, wherever it is saved.
+func (c *Config) ClearSession() error {
+	token := c.SessionToken
+	return c.update(func(cfg *Config) {
+		for user, t := range cfg.Sessions {
+			if t == token {
+				delete(cfg.Sessions, user)
+			}
+		}
+		if cfg.SessionToken == token {
+			cfg.SessionToken = ""
+		}
+	})
+}
+
+// RenameSession moves the session saved for a user to their new name.
+func (c *Config) RenameSession(oldName, newName string) error {
+	return c.update(func(cfg *Config) {
+		token, ok := cfg.Sessions[oldName]
+		if !ok {
+			return
+		}
+		delete(cfg.Sessions, oldName)
+		cfg.Sessions[newName] = token
+	})
+}
+
+// ForgetUser drops the session saved for a deleted user, and the current
+// session too if it was theirs.
+func (c *Config) ForgetUser(user string) error {
+	return c.update(func(cfg *Config) {
+		token, ok := cfg.Sessions[user]
+		if !ok {
+			return
+		}
+		delete(cfg.Sessions, user)
+		if cfg.SessionToken == token {
+			cfg.SessionToken = ""
+		}
+	})
 }
@@ -0,0 +1,215 @@
+package config
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// clearEnv keeps the caller's environment out of a test.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{EnvConfig, EnvProfile, EnvDBURL, EnvUser, EnvSessionToken} {
+		t.Setenv(name, "")
+	}
+}
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadCreatesConfig(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "nested", "config.json")
+
+	cfg, err := Read(Options{Path: path})
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if !cfg.Created() || cfg.Path() != path || cfg.Profile() != DefaultProfile {
+		t.Errorf("Read = created %v, path %q, profile %q", cfg.Created(), cfg.Path(), cfg.Profile())
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("config file wasn't created: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("config file mode = %v, want 0600", perm)
+	}
+
+	cfg, err = Read(Options{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Created() {
+		t.Error("a second Read reported creating the file")
+	}
+}
+
+func TestReadProfiles(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `{"db_url": "postgres://default", "profiles": {"work": {"db_url": "postgres://work"}}}`)
+
+	cfg, err := Read(Options{Path: path, Profile: "work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBURL != "postgres://work" {
+		t.Errorf("work profile db_url = %q", cfg.DBURL)
+	}
+
+	t.Setenv(EnvProfile, "work")
+	if cfg, err = Read(Options{Path: path}); err != nil || cfg.DBURL != "postgres://work" {
+		t.Errorf("GATOR_PROFILE=work: db_url %q, err %v", cfg.DBURL, err)
+	}
+
+	if _, err := Read(Options{Path: path, Profile: "missing"}); err == nil {
+		t.Error("Read of an unknown profile succeeded")
+	}
+	if cfg, err = Read(Options{Path: path, Profile: "missing", CreateProfile: true}); err != nil || cfg.DBURL != "" {
+		t.Errorf("CreateProfile: db_url %q, err %v", cfg.DBURL, err)
+	}
+}
+
+func TestReadEnvOverrides(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `{
+		"db_url": "postgres://file",
+		"session_token": "alice-token",
+		"sessions": {"alice": "alice-token", "bob": "bob-token"}
+	}`)
+	t.Setenv(EnvConfig, path)
+
+	cfg, err := Read(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBURL != "postgres://file" || cfg.SessionToken != "alice-token" || cfg.User() != "" {
+		t.Errorf("without overrides: %q, %q, user %q", cfg.DBURL, cfg.SessionToken, cfg.User())
+	}
+
+	t.Setenv(EnvDBURL, "postgres://env")
+	t.Setenv(EnvUser, "bob")
+	if cfg, err = Read(Options{}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DBURL != "postgres://env" || cfg.SessionToken != "bob-token" || cfg.User() != "bob" {
+		t.Errorf("GATOR_USER=bob: %q, %q, user %q", cfg.DBURL, cfg.SessionToken, cfg.User())
+	}
+
+	// GATOR_USER doesn't fall back to someone else's session.
+	t.Setenv(EnvUser, "carol")
+	if cfg, err = Read(Options{}); err != nil || cfg.SessionToken != "" {
+		t.Errorf("GATOR_USER=carol: token %q, err %v", cfg.SessionToken, err)
+	}
+
+	t.Setenv(EnvSessionToken, "explicit")
+	if cfg, err = Read(Options{}); err != nil || cfg.SessionToken != "explicit" {
+		t.Errorf("GATOR_SESSION_TOKEN: token %q, err %v", cfg.SessionToken, err)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	read := func() Config {
+		t.Helper()
+		cfg, err := Read(Options{Path: path})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cfg
+	}
+
+	cfg := read()
+	if err := cfg.SetSession("alice", "alice-token"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg = read(); cfg.SessionToken != "alice-token" {
+		t.Fatalf("after alice logs in, current token = %q", cfg.SessionToken)
+	}
+
+	// Logging in as bob under GATOR_USER keeps alice current.
+	t.Setenv(EnvUser, "bob")
+	cfg = read()
+	if err := cfg.SetSession("bob", "bob-token"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SessionToken != "bob-token" {
+		t.Errorf("in-memory token after bob logs in = %q", cfg.SessionToken)
+	}
+	t.Setenv(EnvUser, "")
+	cfg = read()
+	if cfg.SessionToken != "alice-token" || cfg.Sessions["bob"] != "bob-token" {
+		t.Fatalf("after bob logs in under GATOR_USER: current %q, sessions %v", cfg.SessionToken, cfg.Sessions)
+	}
+
+	// Logging bob out leaves alice alone, and vice versa.
+	t.Setenv(EnvUser, "bob")
+	cfg = read()
+	if err := cfg.ClearSession(); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvUser, "")
+	cfg = read()
+	if cfg.SessionToken != "alice-token" || cfg.Sessions["bob"] != "" {
+		t.Fatalf("after bob logs out: current %q, sessions %v", cfg.SessionToken, cfg.Sessions)
+	}
+	if err := cfg.ClearSession(); err != nil {
+		t.Fatal(err)
+	}
+	if cfg = read(); cfg.SessionToken != "" || len(cfg.Sessions) != 0 {
+		t.Fatalf("after alice logs out: current %q, sessions %v", cfg.SessionToken, cfg.Sessions)
+	}
+}
+
+func TestRenameAndForgetSession(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "config.json")
+	cfg, err := Read(Options{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.SetSession("alice", "alice-token"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvUser, "bob")
+	bob, err := Read(Options{Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bob.SetSession("bob", "bob-token"); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv(EnvUser, "")
+
+	if err := cfg.RenameSession("alice", "carol"); err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.ForgetUser("bob"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = Read(Options{Path: path}); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"carol": "alice-token"}
+	if cfg.SessionToken != "alice-token" || !maps.Equal(cfg.Sessions, want) {
+		t.Fatalf("after renaming alice and deleting bob: current %q, sessions %v", cfg.SessionToken, cfg.Sessions)
+	}
+
+	if err := cfg.ForgetUser("carol"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg, err = Read(Options{Path: path}); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.SessionToken != "" || len(cfg.Sessions) != 0 {
+		t.Fatalf("after deleting carol: current %q, sessions %v", cfg.SessionToken, cfg.Sessions)
+	}
+}
@@ -0,0 +1,255 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// key describes one setting that the config command can read and change.
+// List values are written as comma-separated strings.
+type key struct {
+	name   string
+	secret bool
+	get    func(*Config) string
+	set    func(*Config, string) error
+	unset  func(*Config)
+}
+
+var keys = []key{
+	{
+		name:  "db_url",
+		get:   func(c *Config) string { return c.DBURL },
+		set:   func(c *Config, v string) error { c.DBURL = v; return nil },
+		unset: func(c *Config) { c.DBURL = "" },
+	},
+	{
+		name:   "session_token",
+		secret: true,
+		get:    func(c *Config) string { return c.SessionToken },
+		set:    func(c *Config, v string) error { c.SessionToken = v; return nil },
+		unset:  func(c *Config) { c.SessionToken = "" },
+	},
+	{
+		name: "strip_params",
+		get: func(c *Config) string {
+			if c.StripParams == nil {
+				return ""
+			}
+			return strings.Join(c.StripParams, ",")
+		},
+		set:   func(c *Config, v string) error { c.StripParams = splitList(v); return nil },
+		unset: func(c *Config) { c.StripParams = nil },
+	},
+	retentionKey("retention.max_age_days", func(r *RetentionConfig) *int { return &r.MaxAgeDays }),
+	retentionKey("retention.max_posts", func(r *RetentionConfig) *int { return &r.MaxPosts }),
+	smtpKey("smtp.host", func(s *SMTPConfig) *string { return &s.Host }),
+	{
+		name: "smtp.port",
+		get: func(c *Config) string {
+			if c.SMTP == nil || c.SMTP.Port == 0 {
+				return ""
+			}
+			return strconv.Itoa(c.SMTP.Port)
+		},
+		set: func(c *Config, v string) error {
+			port, err := strconv.Atoi(v)
+			if err != nil || port <= 0 || port > 65535 {
+				return fmt.Errorf("invalid port %q", v)
+			}
+			smtpConfig(c).Port = port
+			return nil
+		},
+		unset: func(c *Config) {
+			if c.SMTP != nil {
+				c.SMTP.Port = 0
+				dropEmptySMTP(c)
+			}
+		},
+	},
+	smtpKey("smtp.username", func(s *SMTPConfig) *string { return &s.Username }),
+	withSecret(smtpKey("smtp.password", func(s *SMTPConfig) *string { return &s.Password })),
+	smtpKey("smtp.from", func(s *SMTPConfig) *string { return &s.From }),
+	{
+		name: "smtp.to",
+		get: func(c *Config) string {
+			if c.SMTP == nil {
+				return ""
+			}
+			return strings.Join(c.SMTP.To, ",")
+		},
+		set: func(c *Config, v string) error { smtpConfig(c).To = splitList(v); return nil },
+		unset: func(c *Config) {
+			if c.SMTP != nil {
+				c.SMTP.To = nil
+				dropEmptySMTP(c)
+			}
+		},
+	},
+}
+
+func smtpKey(name string, field func(*SMTPConfig) *string) key {
+	return key{
+		name: name,
+		get: func(c *Config) string {
+			if c.SMTP == nil {
+				return ""
+			}
+			return *field(c.SMTP)
+		},
+		set: func(c *Config, v string) error { *field(smtpConfig(c)) = v; return nil },
+		unset: func(c *Config) {
+			if c.SMTP != nil {
+				*field(c.SMTP) = ""
+				dropEmptySMTP(c)
+			}
+		},
+	}
+}
+
+// retentionKey is a non-negative limit in the retention section, which is
+// dropped once every limit is zero.
+func retentionKey(name string, field func(*RetentionConfig) *int) key {
+	return key{
+		name: name,
+		get: func(c *Config) string {
+			if c.Retention == nil || *field(c.Retention) == 0 {
+				return ""
+			}
+			return strconv.Itoa(*field(c.Retention))
+		},
+		set: func(c *Config, v string) error {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return fmt.Errorf("invalid %s %q: want a number, or 0 for no limit", name, v)
+			}
+			if c.Retention == nil {
+				c.Retention = &RetentionConfig{}
+			}
+			*field(c.Retention) = n
+			if *c.Retention == (RetentionConfig{}) {
+				c.Retention = nil
+			}
+			return nil
+		},
+		unset: func(c *Config) {
+			if c.Retention != nil {
+				*field(c.Retention) = 0
+				if *c.Retention == (RetentionConfig{}) {
+					c.Retention = nil
+				}
+			}
+		},
+	}
+}
+
+func withSecret(k key) key {
+	k.secret = true
+	return k
+}
+
+func smtpConfig(c *Config) *SMTPConfig {
+	if c.SMTP == nil {
+		c.SMTP = &SMTPConfig{}
+	}
+	return c.SMTP
+}
+
+func dropEmptySMTP(c *Config) {
+	s := c.SMTP
+	if s.Host == "" && s.Port == 0 && s.Username == "" && s.Password == "" && s.From == "" && len(s.To) == 0 {
+		c.SMTP = nil
+	}
+}
+
+// splitList parses a comma-separated value. An empty value is an empty,
+// non-nil list.
+func splitList(v string) []string {
+	list := []string{}
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func lookupKey(name string) (key, error) {
+	for _, k := range keys {
+		if k.name == name {
+			return k, nil
+		}
+	}
+	return key{}, fmt.Errorf("unknown config key %q: valid keys are %s", name, strings.Join(Keys(), ", "))
+}
+
+// Keys returns the names of all settable keys.
+func Keys() []string {
+	names := make([]string, len(keys))
+	for i, k := range keys {
+		names[i] = k.name
+	}
+	return names
+}
+
+// Get returns the value of key, with secrets redacted unless showSecrets
+// is set.
+func (c *Config) Get(name string, showSecrets bool) (string, error) {
+	k, err := lookupKey(name)
+	if err != nil {
+		return "", err
+	}
+	value := k.get(c)
+	if showSecrets || value == "" {
+		return value, nil
+	}
+	if k.secret {
+		return "********", nil
+	}
+	if k.name == "db_url" {
+		return RedactDBURL(value), nil
+	}
+	return value, nil
+}
+
+// Validate checks that value is acceptable for key without saving it.
+func Validate(name, value string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+	var scratch Config
+	return k.set(&scratch, value)
+}
+
+// Set validates value and saves it under key in the current profile.
+func (c *Config) Set(name, value string) error {
+	if err := Validate(name, value); err != nil {
+		return err
+	}
+	k, _ := lookupKey(name)
+	return c.update(func(cfg *Config) {
+		k.set(cfg, value)
+	})
+}
+
+// Unset removes key from the current profile.
+func (c *Config) Unset(name string) error {
+	k, err := lookupKey(name)
+	if err != nil {
+		return err
+	}
+	return c.update(k.unset)
+}
+
+var dsnPassword = regexp.MustCompile(`(password\s*=\s*)('[^']*'|\S+)`)
+
+// RedactDBURL hides the password in a postgres URL or key=value DSN.
+func RedactDBURL(dbURL string) string {
+	if u, err := url.Parse(dbURL); err == nil && u.Scheme != "" {
+		return u.Redacted()
+	}
+	return dsnPassword.ReplaceAllString(dbURL, "${1}xxxxx")
+}
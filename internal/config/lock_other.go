@@ -0,0 +1,15 @@
+//go:build !unix
+
+package config
+
+import "os"
+
+// lockFile is a no-op where flock isn't available.
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
+
+// tooPermissive is always false where Unix permission bits don't apply.
+func tooPermissive(info os.FileInfo) bool {
+	return false
+}
@@ -0,0 +1,32 @@
+//go:build unix
+
+package config
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile takes an exclusive advisory lock on path+".lock", waiting for
+// other gator processes to release it, and returns the function that
+// releases it.
+func lockFile(path string) (func(), error) {
+	f, err := os.OpenFile(path+".lock", os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		f.Close()
+	}, nil
+}
+
+// tooPermissive reports whether users other than the owner can access the
+// config file.
+func tooPermissive(info os.FileInfo) bool {
+	return info.Mode().Perm()&0077 != 0
+}
@@ -11,23 +11,224 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+const browsePostsForUser = `-- name: BrowsePostsForUser :many
+WITH visible AS (
+    SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.author, posts.content, posts.search_vector, posts.categories, posts.canonical_url, posts.fingerprint, posts.cluster_id, feeds.name AS feed_name, feeds.url AS feed_url,
+        (CASE WHEN $1::text = 'fetched' THEN posts.created_at
+            ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamptz AS sort_at,
+        post_matches_rules(feed_follows.user_id, 'highlight', posts.feed_id, posts.title, posts.description, posts.author, posts.categories) AS highlighted,
+        ARRAY(
+            SELECT DISTINCT dup_feeds.name
+            FROM posts dup
+            JOIN feeds dup_feeds ON dup.feed_id = dup_feeds.id
+            JOIN feed_follows dup_follows ON dup_follows.feed_id = dup.feed_id AND dup_follows.user_id = feed_follows.user_id
+            WHERE dup.cluster_id = posts.cluster_id AND dup.feed_id <> posts.feed_id
+        )::text[] AS also_in,
+        row_number() OVER (PARTITION BY posts.cluster_id ORDER BY posts.created_at, posts.id) AS cluster_rank
+    FROM posts
+    JOIN feeds ON posts.feed_id = feeds.id
+    JOIN feed_follows ON feeds.id = feed_follows.feed_id
+    WHERE feed_follows.user_id = $2
+        AND NOT post_matches_rules(feed_follows.user_id, 'mute', posts.feed_id, posts.title, posts.description, posts.author, posts.categories)
+        AND ($3::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $3::text))
+        AND (COALESCE(cardinality($4::uuid[]), 0) = 0 OR posts.feed_id = ANY($4::uuid[]))
+        AND (COALESCE(cardinality($5::text[]), 0) = 0 OR EXISTS (
+            SELECT 1 FROM feed_follow_tags
+            JOIN tags ON feed_follow_tags.tag_id = tags.id
+            WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name = ANY($5::text[])
+        ))
+        AND ($6::text IS NULL OR posts.author ILIKE '%' || $6::text || '%')
+        AND ($7::timestamptz IS NULL OR (CASE WHEN $1::text = 'fetched' THEN posts.created_at
+            ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamptz >= $7::timestamptz)
+        AND ($8::timestamptz IS NULL OR (CASE WHEN $1::text = 'fetched' THEN posts.created_at
+            ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamptz < $8::timestamptz)
+)
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author, content, search_vector, categories, canonical_url, fingerprint, cluster_id, feed_name, feed_url, sort_at, highlighted, also_in
+FROM visible
+WHERE ($9::timestamptz IS NULL OR (sort_at, id) < ($9::timestamptz, $10::uuid))
+    -- A story shows once, as its first fetched copy among the posts that
+    -- pass the filters, so filtering out or muting one copy can't hide it.
+    AND (NOT $11::boolean OR cluster_rank = 1)
+ORDER BY sort_at DESC, id DESC
+LIMIT $12 OFFSET $13
+`
+
+type BrowsePostsForUserParams struct {
+	SortBy             string
+	UserID             uuid.UUID
+	Query              sql.NullString
+	FeedIds            []uuid.UUID
+	Tags               []string
+	Author             sql.NullString
+	Since              sql.NullTime
+	Until              sql.NullTime
+	AfterAt            sql.NullTime
+	AfterID            uuid.NullUUID
+	CollapseDuplicates bool
+	PostLimit          int32
+	PostOffset         int32
+}
+
+type BrowsePostsForUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Author       sql.NullString
+	Content      sql.NullString
+	SearchVector interface{}
+	Categories   []string
+	CanonicalUrl sql.NullString
+	Fingerprint  sql.NullString
+	ClusterID    uuid.UUID
+	FeedName     string
+	FeedUrl      string
+	SortAt       time.Time
+	Highlighted  bool
+	AlsoIn       []string
+}
+
+func (q *Queries) BrowsePostsForUser(ctx context.Context, arg BrowsePostsForUserParams) ([]BrowsePostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, browsePostsForUser,
+		arg.SortBy,
+		arg.UserID,
+		arg.Query,
+		pq.Array(arg.FeedIds),
+		pq.Array(arg.Tags),
+		arg.Author,
+		arg.Since,
+		arg.Until,
+		arg.AfterAt,
+		arg.AfterID,
+		arg.CollapseDuplicates,
+		arg.PostLimit,
+		arg.PostOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BrowsePostsForUserRow
+	for rows.Next() {
+		var i BrowsePostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.Content,
+			&i.SearchVector,
+			pq.Array(&i.Categories),
+			&i.CanonicalUrl,
+			&i.Fingerprint,
+			&i.ClusterID,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.SortAt,
+			&i.Highlighted,
+			pq.Array(&i.AlsoIn),
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const countUnreadPostsForUser = `-- name: CountUnreadPostsForUser :one
+WITH visible AS (
+    SELECT posts.cluster_id, post_states.read_at,
+        row_number() OVER (PARTITION BY posts.cluster_id ORDER BY posts.created_at, posts.id) AS cluster_rank
+    FROM posts
+    JOIN feeds ON posts.feed_id = feeds.id
+    JOIN feed_follows ON feeds.id = feed_follows.feed_id
+    LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+    WHERE feed_follows.user_id = $1
+        AND NOT post_matches_rules(feed_follows.user_id, 'mute', posts.feed_id, posts.title, posts.description, posts.author, posts.categories)
+        AND ($2::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', $2::text))
+        AND (COALESCE(cardinality($3::uuid[]), 0) = 0 OR posts.feed_id = ANY($3::uuid[]))
+        AND (COALESCE(cardinality($4::text[]), 0) = 0 OR EXISTS (
+            SELECT 1 FROM feed_follow_tags
+            JOIN tags ON feed_follow_tags.tag_id = tags.id
+            WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name = ANY($4::text[])
+        ))
+        AND ($5::text IS NULL OR posts.author ILIKE '%' || $5::text || '%')
+        AND ($6::timestamptz IS NULL OR (CASE WHEN $7::text = 'fetched' THEN posts.created_at
+            ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamptz >= $6::timestamptz)
+)
+SELECT COUNT(*)
+FROM visible
+WHERE read_at IS NULL
+    -- Count the copies BrowsePostsForUser would show.
+    AND (NOT $8::boolean OR cluster_rank = 1)
+`
+
+type CountUnreadPostsForUserParams struct {
+	UserID             uuid.UUID
+	Query              sql.NullString
+	FeedIds            []uuid.UUID
+	Tags               []string
+	Author             sql.NullString
+	Since              sql.NullTime
+	SortBy             string
+	CollapseDuplicates bool
+}
+
+func (q *Queries) CountUnreadPostsForUser(ctx context.Context, arg CountUnreadPostsForUserParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countUnreadPostsForUser,
+		arg.UserID,
+		arg.Query,
+		pq.Array(arg.FeedIds),
+		pq.Array(arg.Tags),
+		arg.Author,
+		arg.Since,
+		arg.SortBy,
+		arg.CollapseDuplicates,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createPost = `-- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, content, categories, canonical_url, fingerprint, cluster_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING id, created_at, updated_at, title, url, description, published_at, feed_id, author, content, search_vector, categories, canonical_url, fingerprint, cluster_id
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Author       sql.NullString
+	Content      sql.NullString
+	Categories   []string
+	CanonicalUrl sql.NullString
+	Fingerprint  sql.NullString
+	ClusterID    uuid.UUID
 }
 
 func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, error) {
@@ -40,6 +241,12 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.Author,
+		arg.Content,
+		pq.Array(arg.Categories),
+		arg.CanonicalUrl,
+		arg.Fingerprint,
+		arg.ClusterID,
 	)
 	var i Post
 	err := row.Scan(
@@ -51,12 +258,69 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (Post, e
 		&i.Description,
 		&i.PublishedAt,
 		&i.FeedID,
+		&i.Author,
+		&i.Content,
+		&i.SearchVector,
+		pq.Array(&i.Categories),
+		&i.CanonicalUrl,
+		&i.Fingerprint,
+		&i.ClusterID,
 	)
 	return i, err
 }
 
+const deleteAllPosts = `-- name: DeleteAllPosts :execrows
+DELETE FROM posts
+`
+
+func (q *Queries) DeleteAllPosts(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAllPosts)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deletePosts = `-- name: DeletePosts :execrows
+DELETE FROM posts WHERE id = ANY($1::uuid[])
+`
+
+func (q *Queries) DeletePosts(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePosts, pq.Array(ids))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const findDuplicatePost = `-- name: FindDuplicatePost :one
+SELECT id, cluster_id FROM posts
+WHERE (canonical_url = $1 OR fingerprint = $2::text)
+    AND created_at >= $3
+ORDER BY created_at, id
+LIMIT 1
+`
+
+type FindDuplicatePostParams struct {
+	CanonicalUrl sql.NullString
+	Fingerprint  sql.NullString
+	Since        time.Time
+}
+
+type FindDuplicatePostRow struct {
+	ID        uuid.UUID
+	ClusterID uuid.UUID
+}
+
+func (q *Queries) FindDuplicatePost(ctx context.Context, arg FindDuplicatePostParams) (FindDuplicatePostRow, error) {
+	row := q.db.QueryRowContext(ctx, findDuplicatePost, arg.CanonicalUrl, arg.Fingerprint, arg.Since)
+	var i FindDuplicatePostRow
+	err := row.Scan(&i.ID, &i.ClusterID)
+	return i, err
+}
+
 const getPostsByUser = `-- name: GetPostsByUser :many
-SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id
+SELECT posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.author, posts.content, posts.search_vector, posts.categories, posts.canonical_url, posts.fingerprint, posts.cluster_id
 FROM posts
 JOIN feeds ON posts.feed_id = feeds.id
 JOIN feed_follows ON feeds.id = feed_follows.feed_id
@@ -88,6 +352,13 @@ func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams)
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.Author,
+			&i.Content,
+			&i.SearchVector,
+			pq.Array(&i.Categories),
+			&i.CanonicalUrl,
+			&i.Fingerprint,
+			&i.ClusterID,
 		); err != nil {
 			return nil, err
 		}
@@ -101,3 +372,107 @@ func (q *Queries) GetPostsByUser(ctx context.Context, arg GetPostsByUserParams)
 	}
 	return items, nil
 }
+
+const listExpiredPosts = `-- name: ListExpiredPosts :many
+SELECT ranked.id,
+    (EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = ranked.id AND post_states.starred_at IS NOT NULL
+    ) OR EXISTS (
+        SELECT 1 FROM feed_follows
+        LEFT JOIN post_states ON post_states.post_id = ranked.id AND post_states.user_id = feed_follows.user_id
+        WHERE feed_follows.feed_id = $1 AND post_states.read_at IS NULL
+    ))::boolean AS protected
+FROM (
+    SELECT id, COALESCE(published_at, created_at) AS posted_at,
+        row_number() OVER (ORDER BY COALESCE(published_at, created_at) DESC, id DESC) AS post_rank
+    FROM posts
+    WHERE feed_id = $1
+) ranked
+WHERE ranked.posted_at < $2::timestamptz
+    OR ranked.post_rank > $3::bigint
+ORDER BY ranked.posted_at, ranked.id
+`
+
+type ListExpiredPostsParams struct {
+	FeedID   uuid.UUID
+	Cutoff   sql.NullTime
+	MaxPosts sql.NullInt64
+}
+
+type ListExpiredPostsRow struct {
+	ID        uuid.UUID
+	Protected bool
+}
+
+func (q *Queries) ListExpiredPosts(ctx context.Context, arg ListExpiredPostsParams) ([]ListExpiredPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listExpiredPosts, arg.FeedID, arg.Cutoff, arg.MaxPosts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListExpiredPostsRow
+	for rows.Next() {
+		var i ListExpiredPostsRow
+		if err := rows.Scan(&i.ID, &i.Protected); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPostURLs = `-- name: ListPostURLs :many
+SELECT id, feed_id, url FROM posts
+ORDER BY created_at, id
+`
+
+type ListPostURLsRow struct {
+	ID     uuid.UUID
+	FeedID uuid.UUID
+	Url    string
+}
+
+func (q *Queries) ListPostURLs(ctx context.Context) ([]ListPostURLsRow, error) {
+	rows, err := q.db.QueryContext(ctx, listPostURLs)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListPostURLsRow
+	for rows.Next() {
+		var i ListPostURLsRow
+		if err := rows.Scan(&i.ID, &i.FeedID, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const updatePostURL = `-- name: UpdatePostURL :exec
+UPDATE posts SET url = $2, canonical_url = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdatePostURLParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdatePostURL(ctx context.Context, arg UpdatePostURLParams) error {
+	_, err := q.db.ExecContext(ctx, updatePostURL, arg.ID, arg.Url)
+	return err
+}
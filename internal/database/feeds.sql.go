@@ -7,13 +7,14 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
 const createFeed = `-- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, added_by)
 VALUES (
     $1,
     $2,
@@ -22,7 +23,7 @@ VALUES (
     $5,
     $6
 )
-RETURNING id, created_at, updated_at, name, url, user_id, last_fetched_at
+RETURNING id, created_at, updated_at, name, url, added_by, last_fetched_at, retention_days, retention_max_posts
 `
 
 type CreateFeedParams struct {
@@ -31,7 +32,7 @@ type CreateFeedParams struct {
 	UpdatedAt time.Time
 	Name      string
 	Url       string
-	UserID    uuid.UUID
+	AddedBy   uuid.NullUUID
 }
 
 func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, error) {
@@ -41,7 +42,7 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		arg.UpdatedAt,
 		arg.Name,
 		arg.Url,
-		arg.UserID,
+		arg.AddedBy,
 	)
 	var i Feed
 	err := row.Scan(
@@ -50,12 +51,26 @@ func (q *Queries) CreateFeed(ctx context.Context, arg CreateFeedParams) (Feed, e
 		&i.UpdatedAt,
 		&i.Name,
 		&i.Url,
-		&i.UserID,
+		&i.AddedBy,
 		&i.LastFetchedAt,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
 	)
 	return i, err
 }
 
+const deleteAllFeeds = `-- name: DeleteAllFeeds :execrows
+DELETE FROM feeds
+`
+
+func (q *Queries) DeleteAllFeeds(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteAllFeeds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteFeed = `-- name: DeleteFeed :exec
 DELETE FROM feeds WHERE id = $1
 `
@@ -65,8 +80,26 @@ func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
 	return err
 }
 
+const deleteUnusedFeeds = `-- name: DeleteUnusedFeeds :execrows
+DELETE FROM feeds
+WHERE NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM posts
+        JOIN post_states ON post_states.post_id = posts.id
+        WHERE posts.feed_id = feeds.id AND post_states.starred_at IS NOT NULL
+    )
+`
+
+func (q *Queries) DeleteUnusedFeeds(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteUnusedFeeds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const getFeed = `-- name: GetFeed :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE name = $1
+SELECT id, created_at, updated_at, name, url, added_by, last_fetched_at, retention_days, retention_max_posts FROM feeds WHERE name = $1
 `
 
 func (q *Queries) GetFeed(ctx context.Context, name string) (Feed, error) {
@@ -78,14 +111,16 @@ func (q *Queries) GetFeed(ctx context.Context, name string) (Feed, error) {
 		&i.UpdatedAt,
 		&i.Name,
 		&i.Url,
-		&i.UserID,
+		&i.AddedBy,
 		&i.LastFetchedAt,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
 	)
 	return i, err
 }
 
 const getFeedByURL = `-- name: GetFeedByURL :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE url = $1
+SELECT id, created_at, updated_at, name, url, added_by, last_fetched_at, retention_days, retention_max_posts FROM feeds WHERE url = $1
 `
 
 func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
@@ -97,14 +132,16 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (Feed, error) {
 		&i.UpdatedAt,
 		&i.Name,
 		&i.Url,
-		&i.UserID,
+		&i.AddedBy,
 		&i.LastFetchedAt,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
 	)
 	return i, err
 }
 
 const getFeeds = `-- name: GetFeeds :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, added_by, last_fetched_at, retention_days, retention_max_posts FROM feeds
 `
 
 func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
@@ -122,8 +159,10 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 			&i.UpdatedAt,
 			&i.Name,
 			&i.Url,
-			&i.UserID,
+			&i.AddedBy,
 			&i.LastFetchedAt,
+			&i.RetentionDays,
+			&i.RetentionMaxPosts,
 		); err != nil {
 			return nil, err
 		}
@@ -138,12 +177,12 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]Feed, error) {
 	return items, nil
 }
 
-const getFeedsByUserID = `-- name: GetFeedsByUserID :many
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds WHERE user_id = $1
+const getFeedsAddedBy = `-- name: GetFeedsAddedBy :many
+SELECT id, created_at, updated_at, name, url, added_by, last_fetched_at, retention_days, retention_max_posts FROM feeds WHERE added_by = $1
 `
 
-func (q *Queries) GetFeedsByUserID(ctx context.Context, userID uuid.UUID) ([]Feed, error) {
-	rows, err := q.db.QueryContext(ctx, getFeedsByUserID, userID)
+func (q *Queries) GetFeedsAddedBy(ctx context.Context, addedBy uuid.NullUUID) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsAddedBy, addedBy)
 	if err != nil {
 		return nil, err
 	}
@@ -157,8 +196,10 @@ func (q *Queries) GetFeedsByUserID(ctx context.Context, userID uuid.UUID) ([]Fee
 			&i.UpdatedAt,
 			&i.Name,
 			&i.Url,
-			&i.UserID,
+			&i.AddedBy,
 			&i.LastFetchedAt,
+			&i.RetentionDays,
+			&i.RetentionMaxPosts,
 		); err != nil {
 			return nil, err
 		}
@@ -174,15 +215,22 @@ func (q *Queries) GetFeedsByUserID(ctx context.Context, userID uuid.UUID) ([]Fee
 }
 
 const getFeedsByUserName = `-- name: GetFeedsByUserName :many
-SELECT feeds.name, feeds.url, users.name AS user_name
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.added_by, feeds.last_fetched_at, feeds.retention_days, feeds.retention_max_posts, users.name AS user_name
 FROM feeds
-JOIN users ON feeds.user_id = users.id
+LEFT JOIN users ON feeds.added_by = users.id
 `
 
 type GetFeedsByUserNameRow struct {
-	Name     string
-	Url      string
-	UserName string
+	ID                uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Name              string
+	Url               string
+	AddedBy           uuid.NullUUID
+	LastFetchedAt     sql.NullTime
+	RetentionDays     sql.NullInt32
+	RetentionMaxPosts sql.NullInt32
+	UserName          sql.NullString
 }
 
 func (q *Queries) GetFeedsByUserName(ctx context.Context) ([]GetFeedsByUserNameRow, error) {
@@ -194,7 +242,18 @@ func (q *Queries) GetFeedsByUserName(ctx context.Context) ([]GetFeedsByUserNameR
 	var items []GetFeedsByUserNameRow
 	for rows.Next() {
 		var i GetFeedsByUserNameRow
-		if err := rows.Scan(&i.Name, &i.Url, &i.UserName); err != nil {
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.AddedBy,
+			&i.LastFetchedAt,
+			&i.RetentionDays,
+			&i.RetentionMaxPosts,
+			&i.UserName,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -209,7 +268,8 @@ func (q *Queries) GetFeedsByUserName(ctx context.Context) ([]GetFeedsByUserNameR
 }
 
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at FROM feeds
+SELECT id, created_at, updated_at, name, url, added_by, last_fetched_at, retention_days, retention_max_posts FROM feeds
+WHERE EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
 ORDER BY last_fetched_at ASC NULLS FIRST
 LIMIT 1
 `
@@ -223,8 +283,10 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.UpdatedAt,
 		&i.Name,
 		&i.Url,
-		&i.UserID,
+		&i.AddedBy,
 		&i.LastFetchedAt,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
 	)
 	return i, err
 }
@@ -238,3 +300,49 @@ func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
 	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
 	return err
 }
+
+const renameFeed = `-- name: RenameFeed :exec
+UPDATE feeds SET name = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type RenameFeedParams struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (q *Queries) RenameFeed(ctx context.Context, arg RenameFeedParams) error {
+	_, err := q.db.ExecContext(ctx, renameFeed, arg.ID, arg.Name)
+	return err
+}
+
+const setFeedRetention = `-- name: SetFeedRetention :exec
+UPDATE feeds SET retention_days = $2, retention_max_posts = $3, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedRetentionParams struct {
+	ID                uuid.UUID
+	RetentionDays     sql.NullInt32
+	RetentionMaxPosts sql.NullInt32
+}
+
+func (q *Queries) SetFeedRetention(ctx context.Context, arg SetFeedRetentionParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedRetention, arg.ID, arg.RetentionDays, arg.RetentionMaxPosts)
+	return err
+}
+
+const updateFeedURL = `-- name: UpdateFeedURL :exec
+UPDATE feeds SET url = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedURLParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedURL, arg.ID, arg.Url)
+	return err
+}
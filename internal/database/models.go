@@ -12,13 +12,15 @@ import (
 )
 
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Name              string
+	Url               string
+	AddedBy           uuid.NullUUID
+	LastFetchedAt     sql.NullTime
+	RetentionDays     sql.NullInt32
+	RetentionMaxPosts sql.NullInt32
 }
 
 type FeedFollow struct {
@@ -29,20 +31,99 @@ type FeedFollow struct {
 	FeedID    uuid.UUID
 }
 
+type FeedFollowTag struct {
+	FeedFollowID uuid.UUID
+	TagID        uuid.UUID
+	CreatedAt    time.Time
+}
+
+type PasswordReset struct {
+	UserID    uuid.UUID
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	Author       sql.NullString
+	Content      sql.NullString
+	SearchVector interface{}
+	Categories   []string
+	CanonicalUrl sql.NullString
+	Fingerprint  sql.NullString
+	ClusterID    uuid.UUID
 }
 
-type User struct {
+type PostRule struct {
+	ID        uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	UserID    uuid.UUID
+	FeedID    uuid.NullUUID
+	Field     string
+	MatchType string
+	Pattern   string
+	Action    string
+}
+
+type PostState struct {
+	UserID    uuid.UUID
+	PostID    uuid.UUID
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	ReadAt    sql.NullTime
+	StarredAt sql.NullTime
+}
+
+type SavedSearch struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	UserID        uuid.UUID
+	Name          string
+	Query         sql.NullString
+	FeedIds       []uuid.UUID
+	Tags          []string
+	Author        sql.NullString
+	WindowSeconds sql.NullInt64
+}
+
+type Session struct {
+	TokenHash string
+	UserID    uuid.UUID
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type Tag struct {
 	ID        uuid.UUID
 	CreatedAt time.Time
 	UpdatedAt time.Time
+	UserID    uuid.UUID
 	Name      string
 }
+
+type User struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Name          string
+	PasswordHash  sql.NullString
+	DeactivatedAt sql.NullTime
+	Role          string
+}
+
+type UserPreference struct {
+	UserID    uuid.UUID
+	Key       string
+	Value     string
+	UpdatedAt time.Time
+}
@@ -0,0 +1,36 @@
+package htmltext
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var tagRegex = regexp.MustCompile(`(?s)<[^>]*>`)
+
+// PlainText turns an HTML post body into readable paragraphs separated by
+// blank lines.
+func PlainText(body string) string {
+	body = strings.NewReplacer("<br>", "\n", "<br/>", "\n", "<br />", "\n", "</p>", "\n\n").Replace(body)
+	body = html.UnescapeString(tagRegex.ReplaceAllString(body, ""))
+	paragraphs := strings.Split(body, "\n\n")
+	for i, p := range paragraphs {
+		paragraphs[i] = strings.Join(strings.Fields(p), " ")
+	}
+	return strings.TrimSpace(strings.Join(paragraphs, "\n\n"))
+}
+
+// Summary returns the plain text of body on a single line, cut at a word
+// boundary so that it is at most maxRunes long (plus an ellipsis).
+func Summary(body string, maxRunes int) string {
+	text := strings.Join(strings.Fields(PlainText(body)), " ")
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	cut := string(runes[:maxRunes])
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return strings.TrimRight(cut, " ,.;:") + "…"
+}
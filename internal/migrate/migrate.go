@@ -0,0 +1,341 @@
+// Package migrate applies goose-style SQL migrations. It records them in
+// goose's goose_db_version table, so databases set up with the goose CLI
+// carry on where they left off.
+package migrate
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration is one numbered schema file, split into its Up and Down
+// sections.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+	// NoTransaction is set by a "-- +goose NO TRANSACTION" annotation.
+	NoTransaction bool
+}
+
+// Status is a migration and, if it has been applied, when.
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load reads every NNN_name.sql file in the top directory of fsys, in
+// version order.
+func Load(fsys fs.FS) ([]Migration, error) {
+	files, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+	var migrations []Migration
+	for _, file := range files {
+		prefix, name, ok := strings.Cut(strings.TrimSuffix(file, ".sql"), "_")
+		if !ok {
+			return nil, fmt.Errorf("migration %s: name must look like 001_description.sql", file)
+		}
+		version, err := strconv.ParseInt(prefix, 10, 64)
+		if err != nil || version < 1 {
+			return nil, fmt.Errorf("migration %s: name must start with a positive version number", file)
+		}
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, err
+		}
+		m, err := parse(string(data))
+		if err != nil {
+			return nil, fmt.Errorf("migration %s: %w", file, err)
+		}
+		m.Version, m.Name = version, name
+		migrations = append(migrations, m)
+	}
+	slices.SortFunc(migrations, func(a, b Migration) int { return int(a.Version - b.Version) })
+	for i := 1; i < len(migrations); i++ {
+		if migrations[i].Version == migrations[i-1].Version {
+			return nil, fmt.Errorf("two migrations have version %d", migrations[i].Version)
+		}
+	}
+	return migrations, nil
+}
+
+// parse splits a migration at its "-- +goose Up" and "-- +goose Down"
+// annotations. Each section is run as a single multi-statement Exec, so
+// StatementBegin/End blocks need no special handling.
+func parse(src string) (Migration, error) {
+	var m Migration
+	var up, down strings.Builder
+	var section *strings.Builder
+	scanner := bufio.NewScanner(strings.NewReader(src))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if annotation, ok := strings.CutPrefix(strings.TrimSpace(line), "-- +goose "); ok {
+			switch strings.TrimSpace(annotation) {
+			case "Up":
+				section = &up
+			case "Down":
+				section = &down
+			case "NO TRANSACTION":
+				m.NoTransaction = true
+			}
+			continue
+		}
+		if section != nil {
+			section.WriteString(line)
+			section.WriteString("\n")
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Migration{}, err
+	}
+	if strings.TrimSpace(up.String()) == "" {
+		return Migration{}, fmt.Errorf("no -- +goose Up section")
+	}
+	m.Up, m.Down = up.String(), down.String()
+	return m, nil
+}
+
+// Dialect holds the SQL that differs between the databases a Migrator
+// can manage.
+type Dialect struct {
+	createVersionTable string
+	versionTableExists string
+}
+
+var (
+	Postgres = Dialect{
+		createVersionTable: `CREATE TABLE IF NOT EXISTS goose_db_version (
+    id SERIAL PRIMARY KEY,
+    version_id BIGINT NOT NULL,
+    is_applied BOOLEAN NOT NULL,
+    tstamp TIMESTAMP DEFAULT NOW()
+)`,
+		versionTableExists: `SELECT to_regclass('goose_db_version') IS NOT NULL`,
+	}
+	SQLite = Dialect{
+		createVersionTable: `CREATE TABLE IF NOT EXISTS goose_db_version (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    version_id INTEGER NOT NULL,
+    is_applied INTEGER NOT NULL,
+    tstamp TIMESTAMP DEFAULT (datetime('now'))
+)`,
+		versionTableExists: `SELECT EXISTS (SELECT 1 FROM sqlite_master WHERE type = 'table' AND name = 'goose_db_version')`,
+	}
+)
+
+// Migrator applies a set of migrations to one database.
+type Migrator struct {
+	db         *sql.DB
+	dialect    Dialect
+	migrations []Migration
+}
+
+func New(db *sql.DB, dialect Dialect, migrations []Migration) *Migrator {
+	return &Migrator{db: db, dialect: dialect, migrations: migrations}
+}
+
+// NewFromFS loads the migrations in fsys; see Load.
+func NewFromFS(db *sql.DB, dialect Dialect, fsys fs.FS) (*Migrator, error) {
+	migrations, err := Load(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return New(db, dialect, migrations), nil
+}
+
+// Latest is the version the code expects: that of the newest migration.
+func (m *Migrator) Latest() int64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// applied returns when each applied version was applied. goose appends a
+// row per change, so the newest row for a version wins. A database without
+// a version table has nothing applied; reading never creates the table.
+func (m *Migrator) applied(ctx context.Context) (map[int64]time.Time, error) {
+	var exists bool
+	if err := m.db.QueryRowContext(ctx, m.dialect.versionTableExists).Scan(&exists); err != nil {
+		return nil, fmt.Errorf("couldn't look for version table: %w", err)
+	}
+	if !exists {
+		return map[int64]time.Time{}, nil
+	}
+	rows, err := m.db.QueryContext(ctx, `SELECT version_id, is_applied, tstamp FROM goose_db_version ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read version table: %w", err)
+	}
+	defer rows.Close()
+	applied := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var isApplied bool
+		var tstamp sql.NullTime
+		if err := rows.Scan(&version, &isApplied, &tstamp); err != nil {
+			return nil, err
+		}
+		if version == 0 {
+			continue
+		}
+		if isApplied {
+			applied[version] = tstamp.Time
+		} else {
+			delete(applied, version)
+		}
+	}
+	return applied, rows.Err()
+}
+
+// Version returns the newest applied version, or 0 for an empty database.
+func (m *Migrator) Version(ctx context.Context) (int64, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	var version int64
+	for v := range applied {
+		version = max(version, v)
+	}
+	return version, nil
+}
+
+// Pending returns the known migrations that haven't been applied, oldest
+// first. Unlike comparing Version with Latest, it also finds migrations
+// skipped below the newest applied one.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Status reports every known migration and whether it has been applied.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, len(m.migrations))
+	for i, migration := range m.migrations {
+		statuses[i] = Status{Migration: migration}
+		if at, ok := applied[migration.Version]; ok {
+			statuses[i].AppliedAt = &at
+		}
+	}
+	return statuses, nil
+}
+
+// Up applies every pending migration up to and including target, calling
+// done after each one.
+func (m *Migrator) Up(ctx context.Context, target int64, done func(Migration)) error {
+	if _, err := m.db.ExecContext(ctx, m.dialect.createVersionTable); err != nil {
+		return fmt.Errorf("couldn't create version table: %w", err)
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	for _, migration := range m.migrations {
+		if migration.Version > target {
+			break
+		}
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		err := m.run(ctx, migration, migration.Up,
+			`INSERT INTO goose_db_version (version_id, is_applied) VALUES ($1, true)`)
+		if err != nil {
+			return fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if done != nil {
+			done(migration)
+		}
+	}
+	return nil
+}
+
+// Down rolls back every applied migration newer than target, newest first,
+// calling done after each one.
+func (m *Migrator) Down(ctx context.Context, target int64, done func(Migration)) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+	for _, migration := range slices.Backward(m.migrations) {
+		if migration.Version <= target {
+			break
+		}
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		err := m.run(ctx, migration, migration.Down,
+			`DELETE FROM goose_db_version WHERE version_id = $1`)
+		if err != nil {
+			return fmt.Errorf("rolling back migration %d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if done != nil {
+			done(migration)
+		}
+	}
+	return nil
+}
+
+// run executes one section of a migration and records it, in a single
+// transaction unless the migration opted out.
+func (m *Migrator) run(ctx context.Context, migration Migration, script, record string) error {
+	if migration.NoTransaction {
+		if strings.TrimSpace(script) != "" {
+			if _, err := m.db.ExecContext(ctx, script); err != nil {
+				return err
+			}
+		}
+		_, err := m.db.ExecContext(ctx, record, migration.Version)
+		return err
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if strings.TrimSpace(script) != "" {
+		if _, err := tx.ExecContext(ctx, script); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.ExecContext(ctx, record, migration.Version); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Previous returns the version just before v among the known migrations,
+// or 0.
+func (m *Migrator) Previous(v int64) int64 {
+	var prev int64
+	for _, migration := range m.migrations {
+		if migration.Version >= v {
+			break
+		}
+		prev = migration.Version
+	}
+	return prev
+}
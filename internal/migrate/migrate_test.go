@@ -0,0 +1,198 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+func TestParse(t *testing.T) {
+	m, err := parse(`-- +goose NO TRANSACTION
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE a (id INTEGER);
+-- +goose StatementEnd
+
+-- +goose Down
+DROP TABLE a;
+`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !m.NoTransaction {
+		t.Error("NO TRANSACTION annotation was ignored")
+	}
+	if got := strings.TrimSpace(m.Up); got != "CREATE TABLE a (id INTEGER);" {
+		t.Errorf("Up = %q", got)
+	}
+	if got := strings.TrimSpace(m.Down); got != "DROP TABLE a;" {
+		t.Errorf("Down = %q", got)
+	}
+}
+
+func TestParseWithoutUp(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"CREATE TABLE a (id INTEGER);",
+		"-- +goose Up\n\n-- +goose Down\nDROP TABLE a;",
+	} {
+		if _, err := parse(src); err == nil {
+			t.Errorf("parse(%q) succeeded, want an error", src)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	fsys := fstest.MapFS{
+		"010_later.sql":      {Data: []byte("-- +goose Up\nSELECT 10;")},
+		"002_second.sql":     {Data: []byte("-- +goose Up\nSELECT 2;")},
+		"001_first_one.sql":  {Data: []byte("-- +goose Up\nSELECT 1;")},
+		"README.md":          {Data: []byte("not a migration")},
+		"sub/003_nested.sql": {Data: []byte("-- +goose Up\nSELECT 3;")},
+	}
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range migrations {
+		got = append(got, strings.Join([]string{m.Name, strings.TrimSpace(m.Up)}, ":"))
+	}
+	want := []string{"first_one:SELECT 1;", "second:SELECT 2;", "later:SELECT 10;"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Load = %v, want %v", got, want)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	for name, fsys := range map[string]fstest.MapFS{
+		"no underscore":     {"001.sql": {Data: []byte("-- +goose Up\nSELECT 1;")}},
+		"no version":        {"first_one.sql": {Data: []byte("-- +goose Up\nSELECT 1;")}},
+		"version zero":      {"000_zero.sql": {Data: []byte("-- +goose Up\nSELECT 1;")}},
+		"duplicate version": {"001_a.sql": {Data: []byte("-- +goose Up\nSELECT 1;")}, "01_b.sql": {Data: []byte("-- +goose Up\nSELECT 1;")}},
+		"no up section":     {"001_a.sql": {Data: []byte("SELECT 1;")}},
+	} {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: Load succeeded, want an error", name)
+		}
+	}
+}
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite3", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func testMigrations() []Migration {
+	return []Migration{
+		{Version: 1, Name: "a", Up: "CREATE TABLE a (id INTEGER);", Down: "DROP TABLE a;"},
+		{Version: 2, Name: "b", Up: "CREATE TABLE b (id INTEGER);", Down: "DROP TABLE b;"},
+		{Version: 3, Name: "c", Up: "CREATE TABLE c (id INTEGER);", Down: "DROP TABLE c;"},
+	}
+}
+
+func TestReadingDoesNotCreateVersionTable(t *testing.T) {
+	db := openTestDB(t)
+	m := New(db, SQLite, testMigrations())
+	ctx := context.Background()
+
+	version, err := m.Version(ctx)
+	if err != nil || version != 0 {
+		t.Fatalf("Version = %d, %v; want 0", version, err)
+	}
+	if _, err := m.Pending(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Status(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		t.Fatal(err)
+	}
+	if tables != 0 {
+		t.Errorf("reading the version left %d tables behind, want none", tables)
+	}
+}
+
+func TestPendingFindsGaps(t *testing.T) {
+	db := openTestDB(t)
+	m := New(db, SQLite, testMigrations())
+	ctx := context.Background()
+	if err := m.Up(ctx, m.Latest(), nil); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a database that skipped migration 2, e.g. after a branch
+	// merge added it below an already applied version.
+	if _, err := db.Exec(`DELETE FROM goose_db_version WHERE version_id = 2`); err != nil {
+		t.Fatal(err)
+	}
+
+	version, err := m.Version(ctx)
+	if err != nil || version != 3 {
+		t.Fatalf("Version = %d, %v; want 3", version, err)
+	}
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 1 || pending[0].Version != 2 {
+		t.Fatalf("Pending = %v, want migration 2", pending)
+	}
+
+	if _, err := db.Exec(`DROP TABLE b`); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(ctx, m.Latest(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := m.Pending(ctx); err != nil || len(pending) != 0 {
+		t.Errorf("after Up, Pending = %v, %v; want none", pending, err)
+	}
+}
+
+func TestUpAndDown(t *testing.T) {
+	db := openTestDB(t)
+	m := New(db, SQLite, testMigrations())
+	ctx := context.Background()
+
+	var ran []int64
+	if err := m.Up(ctx, 2, func(mig Migration) { ran = append(ran, mig.Version) }); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ran, []int64{1, 2}) {
+		t.Errorf("Up to 2 ran %v", ran)
+	}
+	ran = nil
+	if err := m.Up(ctx, m.Latest(), func(mig Migration) { ran = append(ran, mig.Version) }); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ran, []int64{3}) {
+		t.Errorf("Up to latest ran %v, want only 3", ran)
+	}
+
+	ran = nil
+	if err := m.Down(ctx, m.Previous(3), func(mig Migration) { ran = append(ran, mig.Version) }); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(ran, []int64{3}) {
+		t.Errorf("Down to previous ran %v, want only 3", ran)
+	}
+	if version, err := m.Version(ctx); err != nil || version != 2 {
+		t.Errorf("after Down, Version = %d, %v; want 2", version, err)
+	}
+	if _, err := db.Exec(`SELECT * FROM c`); err == nil {
+		t.Error("table c survived rolling back its migration")
+	}
+}
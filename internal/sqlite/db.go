@@ -0,0 +1,180 @@
+//go:build cgo
+
+// Package sqlite runs gator's queries against an embedded SQLite database.
+// Queries has the same methods as database.Queries and returns the same
+// models, so callers don't need to know which database they use. The SQL
+// mirrors sql/queries and has to be kept in step with it by hand;
+// queries_test.go checks the queries most likely to drift.
+//
+// The driver is go-sqlite3, which needs cgo; without it the package is left
+// out and internal/storage refuses sqlite: URLs.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/mattn/go-sqlite3"
+)
+
+// DSN returns the data source name for the database file at path: foreign
+// keys on, which SQLite leaves off by default, WAL so that readers don't
+// block agg, write transactions that take their lock up front, and times
+// read back in the local zone.
+func DSN(path string) string {
+	params := url.Values{
+		"_foreign_keys": {"1"},
+		"_journal_mode": {"WAL"},
+		"_busy_timeout": {"5000"},
+		"_txlock":       {"immediate"},
+		"_loc":          {"auto"},
+	}
+	return "file:" + (&url.URL{Path: path}).EscapedPath() + "?" + params.Encode()
+}
+
+type Queries struct {
+	db database.DBTX
+}
+
+var _ database.Querier = (*Queries)(nil)
+
+func New(db database.DBTX) *Queries {
+	return &Queries{db: db}
+}
+
+func (q *Queries) WithTx(tx *sql.Tx) *Queries {
+	return &Queries{db: tx}
+}
+
+func (q *Queries) exec(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return q.db.ExecContext(ctx, query, utc(args)...)
+}
+
+func (q *Queries) query(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return q.db.QueryContext(ctx, query, utc(args)...)
+}
+
+func (q *Queries) queryRow(ctx context.Context, query string, args ...any) *sql.Row {
+	return q.db.QueryRowContext(ctx, query, utc(args)...)
+}
+
+// execRows runs an :execrows query.
+func (q *Queries) execRows(ctx context.Context, query string, args ...any) (int64, error) {
+	result, err := q.exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// utc converts time arguments to UTC. SQLite stores times as text, which
+// only compares and sorts correctly when every value is in the same zone;
+// now() writes UTC too.
+func utc(args []any) []any {
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			args[i] = v.UTC()
+		case sql.NullTime:
+			if v.Valid {
+				args[i] = v.Time.UTC()
+			} else {
+				args[i] = nil
+			}
+		}
+	}
+	return args
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// collect scans every row of a :many query.
+func collect[T any](rows *sql.Rows, err error, scan func(scanner) (T, error)) ([]T, error) {
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []T
+	for rows.Next() {
+		i, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// jsonArray encodes a list for a column holding a JSON array, which is
+// how this schema stores what Postgres keeps in array columns. A nil list
+// is stored as [], matching Postgres' '{}' default.
+func jsonArray[T any](values []T) (string, error) {
+	if values == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(values)
+	return string(data), err
+}
+
+// jsonList scans a JSON array column into dest.
+func jsonList[T any](dest *[]T) sql.Scanner {
+	return jsonScanner[T]{dest}
+}
+
+type jsonScanner[T any] struct {
+	dest *[]T
+}
+
+func (s jsonScanner[T]) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*s.dest = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), s.dest)
+	case []byte:
+		return json.Unmarshal(v, s.dest)
+	}
+	return fmt.Errorf("cannot scan %T into a list", src)
+}
+
+// timeOf scans a computed timestamp into dest. The driver only converts
+// columns declared TIMESTAMP, so expressions such as COALESCE come back as
+// the stored text.
+func timeOf(dest *time.Time) sql.Scanner {
+	return timeScanner{dest}
+}
+
+type timeScanner struct {
+	dest *time.Time
+}
+
+func (s timeScanner) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*s.dest = v
+		return nil
+	case string:
+		for _, layout := range sqlite3.SQLiteTimestampFormats {
+			if t, err := time.Parse(layout, v); err == nil {
+				*s.dest = t.Local()
+				return nil
+			}
+		}
+		return fmt.Errorf("cannot parse %q as a time", v)
+	}
+	return fmt.Errorf("cannot scan %T into a time", src)
+}
@@ -0,0 +1,117 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const feedFollowColumns = `feed_follows.id, feed_follows.created_at, feed_follows.updated_at, feed_follows.user_id, feed_follows.feed_id`
+
+func scanFeedFollow(row scanner) (database.FeedFollow, error) {
+	var i database.FeedFollow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+	)
+	return i, err
+}
+
+// SQLite can't select from an INSERT in a CTE as Postgres does, but
+// RETURNING may hold subqueries.
+const createFeedFollow = `
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES (?1, ?2, ?3, ?4, ?5)
+RETURNING id, created_at, updated_at, user_id, feed_id,
+    (SELECT feeds.name FROM feeds WHERE feeds.id = feed_follows.feed_id) AS feed_name,
+    (SELECT users.name FROM users WHERE users.id = feed_follows.user_id) AS user_name
+`
+
+func (q *Queries) CreateFeedFollow(ctx context.Context, arg database.CreateFeedFollowParams) (database.CreateFeedFollowRow, error) {
+	row := q.queryRow(ctx, createFeedFollow,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+	)
+	var i database.CreateFeedFollowRow
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.FeedName,
+		&i.UserName,
+	)
+	return i, err
+}
+
+const deleteFeedFollow = `
+DELETE FROM feed_follows
+WHERE id = ?1 AND created_at = ?2
+`
+
+func (q *Queries) DeleteFeedFollow(ctx context.Context, arg database.DeleteFeedFollowParams) error {
+	_, err := q.exec(ctx, deleteFeedFollow, arg.ID, arg.CreatedAt)
+	return err
+}
+
+const getFeedFollowForUser = `
+SELECT ` + feedFollowColumns + ` FROM feed_follows
+WHERE user_id = ?1 AND feed_id = ?2
+`
+
+func (q *Queries) GetFeedFollowForUser(ctx context.Context, arg database.GetFeedFollowForUserParams) (database.FeedFollow, error) {
+	return scanFeedFollow(q.queryRow(ctx, getFeedFollowForUser, arg.UserID, arg.FeedID))
+}
+
+const getFeedFollowerNames = `
+SELECT users.name
+FROM feed_follows
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.feed_id = ?1
+ORDER BY users.name
+`
+
+func (q *Queries) GetFeedFollowerNames(ctx context.Context, feedID uuid.UUID) ([]string, error) {
+	rows, err := q.query(ctx, getFeedFollowerNames, feedID)
+	return collect(rows, err, func(row scanner) (string, error) {
+		var name string
+		err := row.Scan(&name)
+		return name, err
+	})
+}
+
+const getFeedFollowsForUser = `
+SELECT ` + feedFollowColumns + `, feeds.name AS feed_name, feeds.url AS feed_url, users.name AS user_name
+FROM feed_follows
+INNER JOIN feeds ON feed_follows.feed_id = feeds.id
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.user_id = ?1
+`
+
+func (q *Queries) GetFeedFollowsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFeedFollowsForUserRow, error) {
+	rows, err := q.query(ctx, getFeedFollowsForUser, userID)
+	return collect(rows, err, func(row scanner) (database.GetFeedFollowsForUserRow, error) {
+		var i database.GetFeedFollowsForUserRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.UserName,
+		)
+		return i, err
+	})
+}
@@ -0,0 +1,187 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const feedColumns = `feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.added_by, feeds.last_fetched_at, feeds.retention_days, feeds.retention_max_posts`
+
+func scanFeed(row scanner) (database.Feed, error) {
+	var i database.Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.AddedBy,
+		&i.LastFetchedAt,
+		&i.RetentionDays,
+		&i.RetentionMaxPosts,
+	)
+	return i, err
+}
+
+const createFeed = `
+INSERT INTO feeds (id, created_at, updated_at, name, url, added_by)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING ` + feedColumns
+
+func (q *Queries) CreateFeed(ctx context.Context, arg database.CreateFeedParams) (database.Feed, error) {
+	row := q.queryRow(ctx, createFeed,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Url,
+		arg.AddedBy,
+	)
+	return scanFeed(row)
+}
+
+const deleteAllFeeds = `
+DELETE FROM feeds
+`
+
+func (q *Queries) DeleteAllFeeds(ctx context.Context) (int64, error) {
+	return q.execRows(ctx, deleteAllFeeds)
+}
+
+const deleteFeed = `
+DELETE FROM feeds WHERE id = ?1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, deleteFeed, id)
+	return err
+}
+
+const deleteUnusedFeeds = `
+DELETE FROM feeds
+WHERE NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM posts
+        JOIN post_states ON post_states.post_id = posts.id
+        WHERE posts.feed_id = feeds.id AND post_states.starred_at IS NOT NULL
+    )
+`
+
+func (q *Queries) DeleteUnusedFeeds(ctx context.Context) (int64, error) {
+	return q.execRows(ctx, deleteUnusedFeeds)
+}
+
+const getFeed = `
+SELECT ` + feedColumns + ` FROM feeds WHERE name = ?1
+`
+
+func (q *Queries) GetFeed(ctx context.Context, name string) (database.Feed, error) {
+	return scanFeed(q.queryRow(ctx, getFeed, name))
+}
+
+const getFeedByURL = `
+SELECT ` + feedColumns + ` FROM feeds WHERE url = ?1
+`
+
+func (q *Queries) GetFeedByURL(ctx context.Context, url string) (database.Feed, error) {
+	return scanFeed(q.queryRow(ctx, getFeedByURL, url))
+}
+
+const getFeeds = `
+SELECT ` + feedColumns + ` FROM feeds
+`
+
+func (q *Queries) GetFeeds(ctx context.Context) ([]database.Feed, error) {
+	rows, err := q.query(ctx, getFeeds)
+	return collect(rows, err, scanFeed)
+}
+
+const getFeedsAddedBy = `
+SELECT ` + feedColumns + ` FROM feeds WHERE added_by = ?1
+`
+
+func (q *Queries) GetFeedsAddedBy(ctx context.Context, addedBy uuid.NullUUID) ([]database.Feed, error) {
+	rows, err := q.query(ctx, getFeedsAddedBy, addedBy)
+	return collect(rows, err, scanFeed)
+}
+
+const getFeedsByUserName = `
+SELECT ` + feedColumns + `, users.name AS user_name
+FROM feeds
+LEFT JOIN users ON feeds.added_by = users.id
+`
+
+func (q *Queries) GetFeedsByUserName(ctx context.Context) ([]database.GetFeedsByUserNameRow, error) {
+	rows, err := q.query(ctx, getFeedsByUserName)
+	return collect(rows, err, func(row scanner) (database.GetFeedsByUserNameRow, error) {
+		var i database.GetFeedsByUserNameRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.AddedBy,
+			&i.LastFetchedAt,
+			&i.RetentionDays,
+			&i.RetentionMaxPosts,
+			&i.UserName,
+		)
+		return i, err
+	})
+}
+
+const getNextFeedToFetch = `
+SELECT ` + feedColumns + ` FROM feeds
+WHERE EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+ORDER BY last_fetched_at ASC NULLS FIRST
+LIMIT 1
+`
+
+func (q *Queries) GetNextFeedToFetch(ctx context.Context) (database.Feed, error) {
+	return scanFeed(q.queryRow(ctx, getNextFeedToFetch))
+}
+
+const markFeedFetched = `
+UPDATE feeds SET last_fetched_at = now(), updated_at = now()
+WHERE id = ?1
+`
+
+func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
+	_, err := q.exec(ctx, markFeedFetched, id)
+	return err
+}
+
+const renameFeed = `
+UPDATE feeds SET name = ?2, updated_at = now()
+WHERE id = ?1
+`
+
+func (q *Queries) RenameFeed(ctx context.Context, arg database.RenameFeedParams) error {
+	_, err := q.exec(ctx, renameFeed, arg.ID, arg.Name)
+	return err
+}
+
+const setFeedRetention = `
+UPDATE feeds SET retention_days = ?2, retention_max_posts = ?3, updated_at = now()
+WHERE id = ?1
+`
+
+func (q *Queries) SetFeedRetention(ctx context.Context, arg database.SetFeedRetentionParams) error {
+	_, err := q.exec(ctx, setFeedRetention, arg.ID, arg.RetentionDays, arg.RetentionMaxPosts)
+	return err
+}
+
+const updateFeedURL = `
+UPDATE feeds SET url = ?2, updated_at = now()
+WHERE id = ?1
+`
+
+func (q *Queries) UpdateFeedURL(ctx context.Context, arg database.UpdateFeedURLParams) error {
+	_, err := q.exec(ctx, updateFeedURL, arg.ID, arg.Url)
+	return err
+}
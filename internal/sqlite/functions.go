@@ -0,0 +1,188 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// DriverName is the database/sql driver to open DSN with. It is the stock
+// go-sqlite3 driver plus the SQL functions the queries rely on.
+const DriverName = "sqlite3_gator"
+
+func init() {
+	sql.Register(DriverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			if err := conn.RegisterFunc("now", now, false); err != nil {
+				return err
+			}
+			if err := conn.RegisterFunc("rule_matches", ruleMatches, true); err != nil {
+				return err
+			}
+			return conn.RegisterFunc("fts_rank", ftsRank, true)
+		},
+	})
+}
+
+// now stands in for Postgres' NOW(), writing the same UTC text the driver
+// writes for time arguments; see utc.
+func now() string {
+	return time.Now().UTC().Format(sqlite3.SQLiteTimestampFormats[0])
+}
+
+var ruleRegexps, badRuleRegexps sync.Map
+
+// ruleMatches is the Postgres rule_matches function from 010_post_rules:
+// a case-insensitive regex or substring match that is false for NULL.
+// Go's regexp doesn't accept everything Postgres does, so a rule carried
+// over from Postgres may not compile; it matches nothing rather than
+// failing every query that applies rules, and is reported once.
+func ruleMatches(matchType, pattern string, value any) (bool, error) {
+	if matchType == "regex" {
+		re, err := ruleRegexp(pattern)
+		if err != nil {
+			if _, reported := badRuleRegexps.LoadOrStore(pattern, true); !reported {
+				fmt.Fprintf(os.Stderr, "Warning: ignoring rule with unsupported regular expression %q: %v\n", pattern, err)
+			}
+			return false, nil
+		}
+		s, ok := value.(string)
+		return ok && re.MatchString(s), nil
+	}
+	s, ok := value.(string)
+	return ok && strings.Contains(strings.ToLower(s), strings.ToLower(pattern)), nil
+}
+
+func ruleRegexp(pattern string) (*regexp.Regexp, error) {
+	if re, ok := ruleRegexps.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("(?i)" + pattern)
+	if err != nil {
+		return nil, err
+	}
+	ruleRegexps.Store(pattern, re)
+	return re, nil
+}
+
+// ftsWeights are the posts_fts column weights, the same as ts_rank's
+// defaults for the A, B and C weights search_vector gives title,
+// description and content in Postgres.
+var ftsWeights = []float64{1.0, 0.4, 0.2}
+
+// ftsRank scores a row from matchinfo(posts_fts, 'pcx'): for each phrase
+// and column, the hits in this row relative to the hits in all rows,
+// weighted by column.
+func ftsRank(info []byte) (float64, error) {
+	if len(info)%4 != 0 || len(info) < 8 {
+		return 0, fmt.Errorf("fts_rank: malformed matchinfo")
+	}
+	values := make([]uint32, len(info)/4)
+	for i := range values {
+		values[i] = binary.NativeEndian.Uint32(info[i*4:])
+	}
+	phrases, columns := int(values[0]), int(values[1])
+	if len(values) != 2+3*phrases*columns {
+		return 0, fmt.Errorf("fts_rank: malformed matchinfo")
+	}
+	var rank float64
+	for p := range phrases {
+		for c := range min(columns, len(ftsWeights)) {
+			hits := values[2+3*(p*columns+c):]
+			if hits[0] > 0 {
+				rank += ftsWeights[c] * float64(hits[0]) / float64(hits[1])
+			}
+		}
+	}
+	return rank, nil
+}
+
+// ftsQuery translates a query in Postgres' websearch syntax, which is what
+// users type into gator, into an FTS4 MATCH expression: words and "quoted
+// phrases" must all match, "or" between two terms accepts either and a
+// leading "-" excludes a term. Every term is quoted so that punctuation
+// can't be read as FTS syntax. It returns "" for a query with no words.
+func ftsQuery(query string) string {
+	var groups [][]string
+	var excluded []string
+	or := false
+	for query != "" {
+		query = strings.TrimLeftFunc(query, unicode.IsSpace)
+		if query == "" {
+			break
+		}
+		negated := false
+		if query[0] == '-' {
+			negated = true
+			query = query[1:]
+		}
+		var term string
+		quoted := strings.HasPrefix(query, `"`)
+		if quoted {
+			end := strings.IndexByte(query[1:], '"')
+			if end < 0 {
+				term, query = query[1:], ""
+			} else {
+				term, query = query[1:end+1], query[end+2:]
+			}
+		} else {
+			end := strings.IndexFunc(query, unicode.IsSpace)
+			if end < 0 {
+				end = len(query)
+			}
+			term, query = query[:end], query[end:]
+		}
+
+		if !quoted && !negated && strings.EqualFold(term, "or") {
+			or = len(groups) > 0
+			continue
+		}
+		if strings.IndexFunc(term, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) < 0 {
+			continue
+		}
+		term = `"` + strings.ReplaceAll(term, `"`, "") + `"`
+		switch {
+		case negated:
+			excluded = append(excluded, term)
+		case or:
+			groups[len(groups)-1] = append(groups[len(groups)-1], term)
+		default:
+			groups = append(groups, []string{term})
+		}
+		or = false
+	}
+	if len(groups) == 0 {
+		return ""
+	}
+
+	parts := make([]string, len(groups))
+	for i, group := range groups {
+		parts[i] = strings.Join(group, " OR ")
+		if len(group) > 1 {
+			parts[i] = "(" + parts[i] + ")"
+		}
+	}
+	match := strings.Join(parts, " AND ")
+	for _, term := range excluded {
+		match = "(" + match + ") NOT " + term
+	}
+	return match
+}
+
+// ftsArg is ftsQuery for an optional query.
+func ftsArg(query sql.NullString) sql.NullString {
+	if !query.Valid {
+		return query
+	}
+	return sql.NullString{String: ftsQuery(query.String), Valid: true}
+}
@@ -0,0 +1,116 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+func TestFTSQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"", ""},
+		{"go", `"go"`},
+		{"go rust", `"go" AND "rust"`},
+		{"go or rust", `("go" OR "rust")`},
+		{"a OR b or c d", `("a" OR "b" OR "c") AND "d"`},
+		{"or go", `"go"`},
+		{"go or", `"go"`},
+		{`"async book" -docker`, `("async book") NOT "docker"`},
+		{"kubernetes -docker -helm", `(("kubernetes") NOT "docker") NOT "helm"`},
+		{"-docker", ""},
+		{"-- ++ ...", ""},
+		{"c++ -", `"c++"`},
+		{`"unterminated phrase`, `"unterminated phrase"`},
+		{`go say"hi"`, `"go" AND "sayhi"`},
+		{"NEAR AND NOT x*", `"NEAR" AND "AND" AND "NOT" AND "x*"`},
+		{"  spaced\tout  ", `"spaced" AND "out"`},
+	}
+	for _, tt := range tests {
+		if got := ftsQuery(tt.query); got != tt.want {
+			t.Errorf("ftsQuery(%q) = %s, want %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+// matchinfo encodes the 'pcx' matchinfo for one row: hits[p][c] is this
+// row's and all rows' hit counts for phrase p in column c.
+func matchinfo(hits [][][2]uint32) []byte {
+	values := []uint32{uint32(len(hits)), uint32(len(hits[0]))}
+	for _, phrase := range hits {
+		for _, column := range phrase {
+			values = append(values, column[0], column[1], 1)
+		}
+	}
+	info := make([]byte, 4*len(values))
+	for i, v := range values {
+		binary.NativeEndian.PutUint32(info[i*4:], v)
+	}
+	return info
+}
+
+func TestFTSRank(t *testing.T) {
+	tests := []struct {
+		name string
+		hits [][][2]uint32
+		want float64
+	}{
+		{"title only", [][][2]uint32{{{1, 1}, {0, 0}, {0, 0}}}, 1.0},
+		{"weighted columns", [][][2]uint32{{{2, 4}, {0, 1}, {1, 2}}}, 1.0*2/4 + 0.2*1/2},
+		{"description beats content", [][][2]uint32{{{0, 0}, {1, 1}, {0, 0}}}, 0.4},
+		{"two phrases", [][][2]uint32{{{1, 2}, {0, 0}, {0, 0}}, {{0, 0}, {0, 0}, {3, 3}}}, 0.5 + 0.2},
+		{"no hits", [][][2]uint32{{{0, 5}, {0, 5}, {0, 5}}}, 0},
+	}
+	for _, tt := range tests {
+		got, err := ftsRank(matchinfo(tt.hits))
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("%s: ftsRank = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	for _, info := range [][]byte{
+		nil,
+		{1, 2, 3, 4, 5, 6},
+		matchinfo([][][2]uint32{{{1, 1}}})[:12],
+	} {
+		if _, err := ftsRank(info); err == nil {
+			t.Errorf("ftsRank(%v) succeeded, want an error", info)
+		}
+	}
+}
+
+func TestRuleMatches(t *testing.T) {
+	tests := []struct {
+		matchType, pattern string
+		value              any
+		want               bool
+	}{
+		{"keyword", "Go", "Learning go", true},
+		{"keyword", "rust", "Learning go", false},
+		{"keyword", "go", nil, false},
+		{"regex", `^go\b`, "Go 1.30 released", true},
+		{"regex", `kube(rnetes)?`, "KUBERNETES weekly", true},
+		{"regex", `^go\b`, nil, false},
+		// Postgres accepts lookahead; Go doesn't, so the rule is ignored
+		// instead of failing the query.
+		{"regex", `kube(?=r)`, "kubernetes", false},
+	}
+	for _, tt := range tests {
+		got, err := ruleMatches(tt.matchType, tt.pattern, tt.value)
+		if err != nil {
+			t.Errorf("ruleMatches(%s, %q, %v): %v", tt.matchType, tt.pattern, tt.value, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ruleMatches(%s, %q, %v) = %v, want %v", tt.matchType, tt.pattern, tt.value, got, tt.want)
+		}
+	}
+}
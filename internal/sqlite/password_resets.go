@@ -0,0 +1,51 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const hasPasswordReset = `
+SELECT EXISTS (
+    SELECT 1 FROM password_resets
+    WHERE user_id = ?1 AND expires_at > now()
+)
+`
+
+func (q *Queries) HasPasswordReset(ctx context.Context, userID uuid.UUID) (bool, error) {
+	var exists bool
+	err := q.queryRow(ctx, hasPasswordReset, userID).Scan(&exists)
+	return exists, err
+}
+
+const upsertPasswordReset = `
+INSERT INTO password_resets (user_id, token_hash, created_at, expires_at)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT (user_id) DO UPDATE
+SET token_hash = excluded.token_hash,
+    created_at = excluded.created_at,
+    expires_at = excluded.expires_at
+`
+
+func (q *Queries) UpsertPasswordReset(ctx context.Context, arg database.UpsertPasswordResetParams) error {
+	_, err := q.exec(ctx, upsertPasswordReset,
+		arg.UserID,
+		arg.TokenHash,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const usePasswordReset = `
+DELETE FROM password_resets
+WHERE user_id = ?1 AND token_hash = ?2 AND expires_at > now()
+`
+
+func (q *Queries) UsePasswordReset(ctx context.Context, arg database.UsePasswordResetParams) (int64, error) {
+	return q.execRows(ctx, usePasswordReset, arg.UserID, arg.TokenHash)
+}
@@ -0,0 +1,102 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const postRuleColumns = `post_rules.id, post_rules.created_at, post_rules.updated_at, post_rules.user_id, post_rules.feed_id, post_rules.field, post_rules.match_type, post_rules.pattern, post_rules.action`
+
+func scanPostRule(row scanner) (database.PostRule, error) {
+	var i database.PostRule
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.FeedID,
+		&i.Field,
+		&i.MatchType,
+		&i.Pattern,
+		&i.Action,
+	)
+	return i, err
+}
+
+const checkRulePattern = `
+SELECT rule_matches(?1, ?2, '') AS matches
+`
+
+// CheckRulePattern compiles the pattern itself, since rule_matches treats
+// a pattern it can't compile as matching nothing.
+func (q *Queries) CheckRulePattern(ctx context.Context, arg database.CheckRulePatternParams) (bool, error) {
+	if arg.MatchType == "regex" {
+		if _, err := ruleRegexp(arg.Pattern); err != nil {
+			return false, err
+		}
+	}
+	var matches bool
+	err := q.queryRow(ctx, checkRulePattern, arg.MatchType, arg.Pattern).Scan(&matches)
+	return matches, err
+}
+
+const createPostRule = `
+INSERT INTO post_rules (id, created_at, updated_at, user_id, feed_id, field, match_type, pattern, action)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)
+RETURNING ` + postRuleColumns
+
+func (q *Queries) CreatePostRule(ctx context.Context, arg database.CreatePostRuleParams) (database.PostRule, error) {
+	row := q.queryRow(ctx, createPostRule,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+		arg.Field,
+		arg.MatchType,
+		arg.Pattern,
+		arg.Action,
+	)
+	return scanPostRule(row)
+}
+
+const deletePostRule = `
+DELETE FROM post_rules
+WHERE id = ?1 AND user_id = ?2
+`
+
+func (q *Queries) DeletePostRule(ctx context.Context, arg database.DeletePostRuleParams) (int64, error) {
+	return q.execRows(ctx, deletePostRule, arg.ID, arg.UserID)
+}
+
+const getPostRulesForUser = `
+SELECT ` + postRuleColumns + `, feeds.name AS feed_name
+FROM post_rules
+LEFT JOIN feeds ON post_rules.feed_id = feeds.id
+WHERE post_rules.user_id = ?1
+ORDER BY post_rules.created_at
+`
+
+func (q *Queries) GetPostRulesForUser(ctx context.Context, userID uuid.UUID) ([]database.GetPostRulesForUserRow, error) {
+	rows, err := q.query(ctx, getPostRulesForUser, userID)
+	return collect(rows, err, func(row scanner) (database.GetPostRulesForUserRow, error) {
+		var i database.GetPostRulesForUserRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.UserID,
+			&i.FeedID,
+			&i.Field,
+			&i.MatchType,
+			&i.Pattern,
+			&i.Action,
+			&i.FeedName,
+		)
+		return i, err
+	})
+}
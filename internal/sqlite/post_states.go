@@ -0,0 +1,116 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const postStateColumns = `user_id, post_id, created_at, updated_at, read_at, starred_at`
+
+func scanPostState(row scanner) (database.PostState, error) {
+	var i database.PostState
+	err := row.Scan(
+		&i.UserID,
+		&i.PostID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.ReadAt,
+		&i.StarredAt,
+	)
+	return i, err
+}
+
+const getFeedsWithUnreadCounts = `
+SELECT feeds.id, feeds.name, feeds.url,
+    COUNT(posts.id) FILTER (WHERE post_states.read_at IS NULL) AS unread_count
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+LEFT JOIN posts ON posts.feed_id = feeds.id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = ?1
+GROUP BY feeds.id, feeds.name, feeds.url
+ORDER BY feeds.name
+`
+
+func (q *Queries) GetFeedsWithUnreadCounts(ctx context.Context, userID uuid.UUID) ([]database.GetFeedsWithUnreadCountsRow, error) {
+	rows, err := q.query(ctx, getFeedsWithUnreadCounts, userID)
+	return collect(rows, err, func(row scanner) (database.GetFeedsWithUnreadCountsRow, error) {
+		var i database.GetFeedsWithUnreadCountsRow
+		err := row.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.UnreadCount,
+		)
+		return i, err
+	})
+}
+
+const getPostsWithStateForFeed = `
+SELECT posts.id, posts.title, posts.url, posts.description, posts.content, posts.author,
+    posts.published_at, posts.created_at, post_states.read_at, post_states.starred_at
+FROM posts
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = ?1
+WHERE posts.feed_id = ?2
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
+LIMIT ?3
+`
+
+func (q *Queries) GetPostsWithStateForFeed(ctx context.Context, arg database.GetPostsWithStateForFeedParams) ([]database.GetPostsWithStateForFeedRow, error) {
+	rows, err := q.query(ctx, getPostsWithStateForFeed, arg.UserID, arg.FeedID, arg.PostLimit)
+	return collect(rows, err, func(row scanner) (database.GetPostsWithStateForFeedRow, error) {
+		var i database.GetPostsWithStateForFeedRow
+		err := row.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.Content,
+			&i.Author,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.ReadAt,
+			&i.StarredAt,
+		)
+		return i, err
+	})
+}
+
+const markPostRead = `
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at)
+VALUES (?1, ?2, now(), now(), now())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = COALESCE(post_states.read_at, now()), updated_at = now()
+`
+
+func (q *Queries) MarkPostRead(ctx context.Context, arg database.MarkPostReadParams) error {
+	_, err := q.exec(ctx, markPostRead, arg.UserID, arg.PostID)
+	return err
+}
+
+const markPostUnread = `
+UPDATE post_states SET read_at = NULL, updated_at = now()
+WHERE user_id = ?1 AND post_id = ?2
+`
+
+func (q *Queries) MarkPostUnread(ctx context.Context, arg database.MarkPostUnreadParams) error {
+	_, err := q.exec(ctx, markPostUnread, arg.UserID, arg.PostID)
+	return err
+}
+
+const setPostStarred = `
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, starred_at)
+VALUES (?1, ?2, now(), now(), CASE WHEN ?3 THEN now() END)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET starred_at = CASE WHEN ?3 THEN COALESCE(post_states.starred_at, now()) END,
+    updated_at = now()
+`
+
+func (q *Queries) SetPostStarred(ctx context.Context, arg database.SetPostStarredParams) error {
+	_, err := q.exec(ctx, setPostStarred, arg.UserID, arg.PostID, arg.Starred)
+	return err
+}
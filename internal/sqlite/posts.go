@@ -0,0 +1,332 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const postColumns = `posts.id, posts.created_at, posts.updated_at, posts.title, posts.url, posts.description, posts.published_at, posts.feed_id, posts.author, posts.content, posts.categories, posts.canonical_url, posts.fingerprint, posts.cluster_id`
+
+// scanPost reads postColumns. There is no search_vector: posts_fts holds
+// the search index.
+func scanPost(row scanner) (database.Post, error) {
+	var i database.Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.Author,
+		&i.Content,
+		jsonList(&i.Categories),
+		&i.CanonicalUrl,
+		&i.Fingerprint,
+		&i.ClusterID,
+	)
+	return i, err
+}
+
+// ruleApplies is the body of the Postgres post_matches_rules function for
+// a rule r, a post in posts and its follower in feed_follows.
+const ruleApplies = `r.user_id = feed_follows.user_id
+            AND (r.feed_id IS NULL OR r.feed_id = posts.feed_id)
+            AND (
+                (r.field IN ('any', 'title') AND rule_matches(r.match_type, r.pattern, posts.title))
+                OR (r.field IN ('any', 'description') AND rule_matches(r.match_type, r.pattern, posts.description))
+                OR (r.field IN ('any', 'author') AND rule_matches(r.match_type, r.pattern, posts.author))
+                OR (r.field IN ('any', 'category') AND EXISTS (
+                    SELECT 1 FROM json_each(posts.categories) AS category
+                    WHERE rule_matches(r.match_type, r.pattern, category.value)
+                ))
+            )`
+
+const (
+	postHighlighted = `EXISTS (SELECT 1 FROM post_rules r WHERE r.action = 'highlight' AND ` + ruleApplies + `)`
+	postMuted       = `EXISTS (SELECT 1 FROM post_rules r WHERE r.action = 'mute' AND ` + ruleApplies + `)`
+)
+
+// postSortAt is sort_at in BrowsePostsForUser, with ?1 as sort_by.
+const postSortAt = `(CASE WHEN ?1 = 'fetched' THEN posts.created_at
+        ELSE COALESCE(posts.published_at, posts.created_at) END)`
+
+const browsePostsForUser = `
+WITH visible AS (
+    SELECT posts.*, feeds.name AS feed_name, feeds.url AS feed_url,
+        ` + postSortAt + ` AS sort_at,
+        ` + postHighlighted + ` AS highlighted,
+        (
+            SELECT json_group_array(DISTINCT dup_feeds.name)
+            FROM posts dup
+            JOIN feeds dup_feeds ON dup.feed_id = dup_feeds.id
+            JOIN feed_follows dup_follows ON dup_follows.feed_id = dup.feed_id AND dup_follows.user_id = feed_follows.user_id
+            WHERE dup.cluster_id = posts.cluster_id AND dup.feed_id <> posts.feed_id
+        ) AS also_in,
+        row_number() OVER (PARTITION BY posts.cluster_id ORDER BY posts.created_at, posts.id) AS cluster_rank
+    FROM posts
+    JOIN feeds ON posts.feed_id = feeds.id
+    JOIN feed_follows ON feeds.id = feed_follows.feed_id
+    WHERE feed_follows.user_id = ?2
+        AND NOT ` + postMuted + `
+        AND (?3 IS NULL OR posts.seq IN (SELECT docid FROM posts_fts WHERE posts_fts MATCH ?3))
+        AND (json_array_length(?4) = 0 OR posts.feed_id IN (SELECT value FROM json_each(?4)))
+        AND (json_array_length(?5) = 0 OR EXISTS (
+            SELECT 1 FROM feed_follow_tags
+            JOIN tags ON feed_follow_tags.tag_id = tags.id
+            WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name IN (SELECT value FROM json_each(?5))
+        ))
+        AND (?6 IS NULL OR posts.author LIKE '%' || ?6 || '%')
+        AND (?7 IS NULL OR ` + postSortAt + ` >= ?7)
+        AND (?8 IS NULL OR ` + postSortAt + ` < ?8)
+)
+SELECT ` + postColumns + `, posts.feed_name, posts.feed_url, posts.sort_at, posts.highlighted, posts.also_in
+FROM visible AS posts
+WHERE (?9 IS NULL OR (posts.sort_at, posts.id) < (?9, ?10))
+    -- A story shows once, as its first fetched copy among the posts that
+    -- pass the filters, so filtering out or muting one copy can't hide it.
+    AND (NOT ?11 OR posts.cluster_rank = 1)
+ORDER BY posts.sort_at DESC, posts.id DESC
+LIMIT ?12 OFFSET ?13
+`
+
+func (q *Queries) BrowsePostsForUser(ctx context.Context, arg database.BrowsePostsForUserParams) ([]database.BrowsePostsForUserRow, error) {
+	feedIDs, err := jsonArray(arg.FeedIds)
+	if err != nil {
+		return nil, err
+	}
+	tags, err := jsonArray(arg.Tags)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := q.query(ctx, browsePostsForUser,
+		arg.SortBy,
+		arg.UserID,
+		ftsArg(arg.Query),
+		feedIDs,
+		tags,
+		arg.Author,
+		arg.Since,
+		arg.Until,
+		arg.AfterAt,
+		arg.AfterID,
+		arg.CollapseDuplicates,
+		arg.PostLimit,
+		arg.PostOffset,
+	)
+	return collect(rows, err, func(row scanner) (database.BrowsePostsForUserRow, error) {
+		var i database.BrowsePostsForUserRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.Content,
+			jsonList(&i.Categories),
+			&i.CanonicalUrl,
+			&i.Fingerprint,
+			&i.ClusterID,
+			&i.FeedName,
+			&i.FeedUrl,
+			timeOf(&i.SortAt),
+			&i.Highlighted,
+			jsonList(&i.AlsoIn),
+		)
+		return i, err
+	})
+}
+
+const countUnreadPostsForUser = `
+WITH visible AS (
+    SELECT posts.cluster_id, post_states.read_at,
+        row_number() OVER (PARTITION BY posts.cluster_id ORDER BY posts.created_at, posts.id) AS cluster_rank
+    FROM posts
+    JOIN feeds ON posts.feed_id = feeds.id
+    JOIN feed_follows ON feeds.id = feed_follows.feed_id
+    LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+    WHERE feed_follows.user_id = ?2
+        AND NOT ` + postMuted + `
+        AND (?3 IS NULL OR posts.seq IN (SELECT docid FROM posts_fts WHERE posts_fts MATCH ?3))
+        AND (json_array_length(?4) = 0 OR posts.feed_id IN (SELECT value FROM json_each(?4)))
+        AND (json_array_length(?5) = 0 OR EXISTS (
+            SELECT 1 FROM feed_follow_tags
+            JOIN tags ON feed_follow_tags.tag_id = tags.id
+            WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name IN (SELECT value FROM json_each(?5))
+        ))
+        AND (?6 IS NULL OR posts.author LIKE '%' || ?6 || '%')
+        AND (?7 IS NULL OR ` + postSortAt + ` >= ?7)
+)
+SELECT COUNT(*)
+FROM visible
+WHERE read_at IS NULL
+    -- Count the copies BrowsePostsForUser would show.
+    AND (NOT ?8 OR cluster_rank = 1)
+`
+
+func (q *Queries) CountUnreadPostsForUser(ctx context.Context, arg database.CountUnreadPostsForUserParams) (int64, error) {
+	feedIDs, err := jsonArray(arg.FeedIds)
+	if err != nil {
+		return 0, err
+	}
+	tags, err := jsonArray(arg.Tags)
+	if err != nil {
+		return 0, err
+	}
+	row := q.queryRow(ctx, countUnreadPostsForUser,
+		arg.SortBy,
+		arg.UserID,
+		ftsArg(arg.Query),
+		feedIDs,
+		tags,
+		arg.Author,
+		arg.Since,
+		arg.CollapseDuplicates,
+	)
+	var count int64
+	err = row.Scan(&count)
+	return count, err
+}
+
+const createPost = `
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, content, categories, canonical_url, fingerprint, cluster_id)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11, ?12, ?13, ?14)
+RETURNING ` + postColumns
+
+func (q *Queries) CreatePost(ctx context.Context, arg database.CreatePostParams) (database.Post, error) {
+	categories, err := jsonArray(arg.Categories)
+	if err != nil {
+		return database.Post{}, err
+	}
+	row := q.queryRow(ctx, createPost,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Title,
+		arg.Url,
+		arg.Description,
+		arg.PublishedAt,
+		arg.FeedID,
+		arg.Author,
+		arg.Content,
+		categories,
+		arg.CanonicalUrl,
+		arg.Fingerprint,
+		arg.ClusterID,
+	)
+	return scanPost(row)
+}
+
+const deleteAllPosts = `
+DELETE FROM posts
+`
+
+func (q *Queries) DeleteAllPosts(ctx context.Context) (int64, error) {
+	return q.execRows(ctx, deleteAllPosts)
+}
+
+const deletePosts = `
+DELETE FROM posts WHERE id IN (SELECT value FROM json_each(?1))
+`
+
+func (q *Queries) DeletePosts(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	idList, err := jsonArray(ids)
+	if err != nil {
+		return 0, err
+	}
+	return q.execRows(ctx, deletePosts, idList)
+}
+
+const findDuplicatePost = `
+SELECT id, cluster_id FROM posts
+WHERE (canonical_url = ?1 OR fingerprint = ?2)
+    AND created_at >= ?3
+ORDER BY created_at, id
+LIMIT 1
+`
+
+func (q *Queries) FindDuplicatePost(ctx context.Context, arg database.FindDuplicatePostParams) (database.FindDuplicatePostRow, error) {
+	row := q.queryRow(ctx, findDuplicatePost, arg.CanonicalUrl, arg.Fingerprint, arg.Since)
+	var i database.FindDuplicatePostRow
+	err := row.Scan(&i.ID, &i.ClusterID)
+	return i, err
+}
+
+const getPostsByUser = `
+SELECT ` + postColumns + `
+FROM posts
+JOIN feeds ON posts.feed_id = feeds.id
+JOIN feed_follows ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = ?1
+ORDER BY posts.created_at DESC
+LIMIT ?2
+`
+
+func (q *Queries) GetPostsByUser(ctx context.Context, arg database.GetPostsByUserParams) ([]database.Post, error) {
+	rows, err := q.query(ctx, getPostsByUser, arg.UserID, arg.Limit)
+	return collect(rows, err, scanPost)
+}
+
+const listExpiredPosts = `
+SELECT ranked.id,
+    EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = ranked.id AND post_states.starred_at IS NOT NULL
+    ) OR EXISTS (
+        SELECT 1 FROM feed_follows
+        LEFT JOIN post_states ON post_states.post_id = ranked.id AND post_states.user_id = feed_follows.user_id
+        WHERE feed_follows.feed_id = ?1 AND post_states.read_at IS NULL
+    ) AS protected
+FROM (
+    SELECT id, COALESCE(published_at, created_at) AS posted_at,
+        row_number() OVER (ORDER BY COALESCE(published_at, created_at) DESC, id DESC) AS post_rank
+    FROM posts
+    WHERE feed_id = ?1
+) ranked
+WHERE ranked.posted_at < ?2
+    OR ranked.post_rank > ?3
+ORDER BY ranked.posted_at, ranked.id
+`
+
+func (q *Queries) ListExpiredPosts(ctx context.Context, arg database.ListExpiredPostsParams) ([]database.ListExpiredPostsRow, error) {
+	rows, err := q.query(ctx, listExpiredPosts, arg.FeedID, arg.Cutoff, arg.MaxPosts)
+	return collect(rows, err, func(row scanner) (database.ListExpiredPostsRow, error) {
+		var i database.ListExpiredPostsRow
+		err := row.Scan(&i.ID, &i.Protected)
+		return i, err
+	})
+}
+
+const listPostURLs = `
+SELECT id, feed_id, url FROM posts
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListPostURLs(ctx context.Context) ([]database.ListPostURLsRow, error) {
+	rows, err := q.query(ctx, listPostURLs)
+	return collect(rows, err, func(row scanner) (database.ListPostURLsRow, error) {
+		var i database.ListPostURLsRow
+		err := row.Scan(&i.ID, &i.FeedID, &i.Url)
+		return i, err
+	})
+}
+
+const updatePostURL = `
+UPDATE posts SET url = ?2, canonical_url = ?2, updated_at = now()
+WHERE id = ?1
+`
+
+func (q *Queries) UpdatePostURL(ctx context.Context, arg database.UpdatePostURLParams) error {
+	_, err := q.exec(ctx, updatePostURL, arg.ID, arg.Url)
+	return err
+}
@@ -0,0 +1,557 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/migrate"
+	"github.com/Grumpster-Dev/gator/sql/sqlite/schema"
+	"github.com/google/uuid"
+)
+
+// These tests pin down what the hand-written SQLite queries return for a
+// fixed set of rows. The expectations are what the Postgres queries in
+// sql/queries return for the same rows, so a change to either side that
+// isn't made to the other shows up here.
+
+var t0 = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestQueries(t *testing.T) *Queries {
+	t.Helper()
+	db, err := sql.Open(DriverName, DSN(filepath.Join(t.TempDir(), "gator.db")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	m, err := migrate.NewFromFS(db, migrate.SQLite, schema.FS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Up(context.Background(), m.Latest(), nil); err != nil {
+		t.Fatal(err)
+	}
+	return New(db)
+}
+
+// fixture builds rows through q and remembers them by short names.
+type fixture struct {
+	t       *testing.T
+	q       *Queries
+	users   map[string]database.User
+	feeds   map[string]database.Feed
+	follows map[string]database.CreateFeedFollowRow
+	posts   map[string]database.Post
+	names   map[uuid.UUID]string
+}
+
+func newFixture(t *testing.T) *fixture {
+	return &fixture{
+		t:       t,
+		q:       newTestQueries(t),
+		users:   make(map[string]database.User),
+		feeds:   make(map[string]database.Feed),
+		follows: make(map[string]database.CreateFeedFollowRow),
+		posts:   make(map[string]database.Post),
+		names:   make(map[uuid.UUID]string),
+	}
+}
+
+func (f *fixture) user(name string) database.User {
+	f.t.Helper()
+	u, err := f.q.CreateUser(context.Background(), database.CreateUserParams{
+		ID:        uuid.New(),
+		CreatedAt: t0,
+		UpdatedAt: t0,
+		Name:      name,
+		Role:      "member",
+	})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.users[name] = u
+	return u
+}
+
+func (f *fixture) feed(name string) database.Feed {
+	f.t.Helper()
+	feed, err := f.q.CreateFeed(context.Background(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: t0,
+		UpdatedAt: t0,
+		Name:      name,
+		Url:       "https://" + name + ".example.com/feed",
+	})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.feeds[name] = feed
+	return feed
+}
+
+func (f *fixture) follow(user, feed string, tags ...string) {
+	f.t.Helper()
+	ctx := context.Background()
+	follow, err := f.q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: t0,
+		UpdatedAt: t0,
+		UserID:    f.users[user].ID,
+		FeedID:    f.feeds[feed].ID,
+	})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.follows[user+"/"+feed] = follow
+	for _, name := range tags {
+		tag, err := f.q.UpsertTag(ctx, database.UpsertTagParams{
+			ID:        uuid.New(),
+			CreatedAt: t0,
+			UpdatedAt: t0,
+			UserID:    f.users[user].ID,
+			Name:      name,
+		})
+		if err != nil {
+			f.t.Fatal(err)
+		}
+		_, err = f.q.AddFollowTag(ctx, database.AddFollowTagParams{FeedFollowID: follow.ID, TagID: tag.ID, CreatedAt: t0})
+		if err != nil {
+			f.t.Fatal(err)
+		}
+	}
+}
+
+type testPost struct {
+	name, feed, title, author, content string
+	categories                         []string
+	published                          *time.Time
+	fetched                            time.Time
+	// cluster names the post this one duplicates, if any.
+	cluster string
+}
+
+func (f *fixture) post(p testPost) {
+	f.t.Helper()
+	id := uuid.New()
+	cluster := id
+	if p.cluster != "" {
+		cluster = f.posts[p.cluster].ClusterID
+	}
+	params := database.CreatePostParams{
+		ID:           id,
+		CreatedAt:    p.fetched,
+		UpdatedAt:    p.fetched,
+		Title:        p.title,
+		Url:          f.feeds[p.feed].Url + "/" + p.name,
+		FeedID:       f.feeds[p.feed].ID,
+		Author:       sql.NullString{String: p.author, Valid: p.author != ""},
+		Content:      sql.NullString{String: p.content, Valid: p.content != ""},
+		Categories:   orEmpty(p.categories),
+		CanonicalUrl: sql.NullString{String: "https://example.com/" + p.name, Valid: true},
+		ClusterID:    cluster,
+	}
+	if p.published != nil {
+		params.PublishedAt = sql.NullTime{Time: *p.published, Valid: true}
+	}
+	post, err := f.q.CreatePost(context.Background(), params)
+	if err != nil {
+		f.t.Fatal(err)
+	}
+	f.posts[p.name] = post
+	f.names[post.ID] = p.name
+}
+
+func (f *fixture) rule(user, action, field, matchType, pattern string) {
+	f.t.Helper()
+	f.feedRule(user, "", action, field, matchType, pattern)
+}
+
+// feedRule adds a rule scoped to one feed, or to all of them if feed is
+// empty.
+func (f *fixture) feedRule(user, feed, action, field, matchType, pattern string) {
+	f.t.Helper()
+	var feedID uuid.NullUUID
+	if feed != "" {
+		feedID = uuid.NullUUID{UUID: f.feeds[feed].ID, Valid: true}
+	}
+	_, err := f.q.CreatePostRule(context.Background(), database.CreatePostRuleParams{
+		ID:        uuid.New(),
+		CreatedAt: t0,
+		UpdatedAt: t0,
+		UserID:    f.users[user].ID,
+		FeedID:    feedID,
+		Field:     field,
+		MatchType: matchType,
+		Pattern:   pattern,
+		Action:    action,
+	})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func orEmpty(list []string) []string {
+	if list == nil {
+		return []string{}
+	}
+	return list
+}
+
+func at(hours float64) *time.Time {
+	t := t0.Add(time.Duration(hours * float64(time.Hour)))
+	return &t
+}
+
+// browseFixture: ada follows go and rust (tagged lang) and news; bob only
+// follows news. "dup" is the go feed's release post again, fetched later
+// through rust.
+func browseFixture(t *testing.T) *fixture {
+	f := newFixture(t)
+	f.user("ada")
+	f.user("bob")
+	f.feed("go")
+	f.feed("rust")
+	f.feed("news")
+	f.follow("ada", "go", "lang")
+	f.follow("ada", "rust", "lang")
+	f.follow("ada", "news")
+	f.follow("bob", "news")
+
+	f.post(testPost{name: "generics", feed: "go", title: "Go generics explained", author: "Rob Pike",
+		content: "Type parameters arrive.", published: at(1), fetched: *at(10)})
+	f.post(testPost{name: "release", feed: "go", title: "Go 1.30 released", author: "Go Team",
+		content: "The release notes.", published: at(2), fetched: *at(2)})
+	f.post(testPost{name: "async", feed: "rust", title: "The async book", author: "Ferris",
+		categories: []string{"Async", "books"}, published: at(3), fetched: *at(3)})
+	f.post(testPost{name: "dup", feed: "rust", title: "Go 1.30 released", author: "Go Team",
+		content: "The release notes.", published: at(4), fetched: *at(4), cluster: "release"})
+	f.post(testPost{name: "kube", feed: "news", title: "Kubernetes weekly",
+		content: "Clusters and docker images.", published: at(5), fetched: *at(5)})
+	f.post(testPost{name: "sports", feed: "news", title: "Sports results", fetched: *at(6)})
+
+	f.rule("ada", "mute", "title", "keyword", "SPORTS")
+	f.rule("ada", "highlight", "title", "regex", `^kube(rnetes)?\b`)
+	f.rule("ada", "highlight", "category", "keyword", "async")
+	// A pattern Postgres accepts but Go can't compile, as a restored
+	// archive might carry; it must match nothing rather than fail browse.
+	f.rule("ada", "highlight", "any", "regex", `release(?=s)`)
+	return f
+}
+
+func TestBrowsePostsForUser(t *testing.T) {
+	f := browseFixture(t)
+	ada, bob := f.users["ada"].ID, f.users["bob"].ID
+	base := database.BrowsePostsForUserParams{SortBy: "published", UserID: ada, PostLimit: 100}
+
+	tests := []struct {
+		name   string
+		change func(p *database.BrowsePostsForUserParams)
+		// want lists post names in order; a trailing * marks highlighted
+		// posts.
+		want []string
+	}{
+		{"everything but muted", nil,
+			[]string{"kube*", "dup", "async*", "release", "generics"}},
+		{"another user's rules don't apply", func(p *database.BrowsePostsForUserParams) { p.UserID = bob },
+			[]string{"sports", "kube"}},
+		{"sorted by fetch time", func(p *database.BrowsePostsForUserParams) { p.SortBy = "fetched" },
+			[]string{"generics", "kube*", "dup", "async*", "release"}},
+		{"duplicates collapsed to the first fetched", func(p *database.BrowsePostsForUserParams) { p.CollapseDuplicates = true },
+			[]string{"kube*", "async*", "release", "generics"}},
+		{"duplicates collapsed within the feed filter", func(p *database.BrowsePostsForUserParams) {
+			p.CollapseDuplicates = true
+			p.FeedIds = []uuid.UUID{f.feeds["rust"].ID}
+		}, []string{"dup", "async*"}},
+		{"feed filter", func(p *database.BrowsePostsForUserParams) {
+			p.FeedIds = []uuid.UUID{f.feeds["go"].ID, f.feeds["news"].ID}
+		}, []string{"kube*", "release", "generics"}},
+		{"tag filter", func(p *database.BrowsePostsForUserParams) { p.Tags = []string{"lang", "unused"} },
+			[]string{"dup", "async*", "release", "generics"}},
+		{"author, case-insensitive", func(p *database.BrowsePostsForUserParams) {
+			p.Author = sql.NullString{String: "pike", Valid: true}
+		}, []string{"generics"}},
+		{"since and until", func(p *database.BrowsePostsForUserParams) {
+			p.Since = sql.NullTime{Time: *at(2), Valid: true}
+			p.Until = sql.NullTime{Time: *at(4), Valid: true}
+		}, []string{"async*", "release"}},
+		{"since in another zone", func(p *database.BrowsePostsForUserParams) {
+			p.Since = sql.NullTime{Time: at(3).In(time.FixedZone("UTC-5", -5*60*60)), Valid: true}
+		}, []string{"kube*", "dup", "async*"}},
+		{"after cursor", func(p *database.BrowsePostsForUserParams) {
+			p.AfterAt = sql.NullTime{Time: *at(4), Valid: true}
+			p.AfterID = uuid.NullUUID{UUID: f.posts["dup"].ID, Valid: true}
+		}, []string{"async*", "release", "generics"}},
+		{"limit and offset", func(p *database.BrowsePostsForUserParams) {
+			p.PostLimit, p.PostOffset = 2, 1
+		}, []string{"dup", "async*"}},
+		{"search", func(p *database.BrowsePostsForUserParams) {
+			p.Query = sql.NullString{String: "release", Valid: true}
+		}, []string{"dup", "release"}},
+		{"search with negation", func(p *database.BrowsePostsForUserParams) {
+			p.Query = sql.NullString{String: "go -generics", Valid: true}
+		}, []string{"dup", "release"}},
+		{"search with or", func(p *database.BrowsePostsForUserParams) {
+			p.Query = sql.NullString{String: "docker or generics", Valid: true}
+		}, []string{"kube*", "generics"}},
+		{"search stems words", func(p *database.BrowsePostsForUserParams) {
+			p.Query = sql.NullString{String: "clustering", Valid: true}
+		}, []string{"kube*"}},
+		{"search without words matches nothing", func(p *database.BrowsePostsForUserParams) {
+			p.Query = sql.NullString{String: "-", Valid: true}
+		}, nil},
+	}
+	for _, tt := range tests {
+		params := base
+		if tt.change != nil {
+			tt.change(&params)
+		}
+		rows, err := f.q.BrowsePostsForUser(context.Background(), params)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, row := range rows {
+			name := f.names[row.ID]
+			if row.Highlighted {
+				name += "*"
+			}
+			got = append(got, name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestBrowsePostsForUserDuplicates checks that a story's copy in one feed
+// stands in for it when the filters or mute rules drop the first fetched
+// copy in another.
+func TestBrowsePostsForUserDuplicates(t *testing.T) {
+	f := newFixture(t)
+	f.user("ada")
+	f.feed("blog")
+	f.feed("planet")
+	f.follow("ada", "blog")
+	f.follow("ada", "planet", "go")
+	f.post(testPost{name: "first", feed: "blog", title: "Go 1.30 released", published: at(1), fetched: *at(1)})
+	f.post(testPost{name: "second", feed: "planet", title: "Go 1.30 released", published: at(3), fetched: *at(3),
+		cluster: "first"})
+	base := database.BrowsePostsForUserParams{
+		SortBy:             "published",
+		UserID:             f.users["ada"].ID,
+		CollapseDuplicates: true,
+		PostLimit:          100,
+	}
+
+	tests := []struct {
+		name   string
+		change func(p *database.BrowsePostsForUserParams)
+		want   []string
+	}{
+		{"first fetched copy", nil, []string{"first"}},
+		{"first copy untagged", func(p *database.BrowsePostsForUserParams) { p.Tags = []string{"go"} },
+			[]string{"second"}},
+		{"first copy before since", func(p *database.BrowsePostsForUserParams) {
+			p.Since = sql.NullTime{Time: *at(2), Valid: true}
+		}, []string{"second"}},
+		{"first copy muted in its feed", func(p *database.BrowsePostsForUserParams) {
+			f.feedRule("ada", "blog", "mute", "title", "keyword", "go")
+		}, []string{"second"}},
+	}
+	for _, tt := range tests {
+		params := base
+		if tt.change != nil {
+			tt.change(&params)
+		}
+		rows, err := f.q.BrowsePostsForUser(context.Background(), params)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, row := range rows {
+			got = append(got, f.names[row.ID])
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCountUnreadPostsForUser(t *testing.T) {
+	f := browseFixture(t)
+	base := database.CountUnreadPostsForUserParams{SortBy: "published", UserID: f.users["ada"].ID}
+
+	tests := []struct {
+		name   string
+		change func(p *database.CountUnreadPostsForUserParams)
+		want   int64
+	}{
+		{"everything but muted", nil, 5},
+		{"duplicates collapsed", func(p *database.CountUnreadPostsForUserParams) { p.CollapseDuplicates = true }, 4},
+		{"duplicates collapsed within the feed filter", func(p *database.CountUnreadPostsForUserParams) {
+			p.CollapseDuplicates = true
+			p.FeedIds = []uuid.UUID{f.feeds["rust"].ID}
+		}, 2},
+		{"since by publish time", func(p *database.CountUnreadPostsForUserParams) {
+			p.CollapseDuplicates = true
+			p.Since = sql.NullTime{Time: *at(3.5), Valid: true}
+		}, 2},
+		{"since by fetch time", func(p *database.CountUnreadPostsForUserParams) {
+			p.SortBy = "fetched"
+			p.Since = sql.NullTime{Time: *at(3.5), Valid: true}
+		}, 3},
+		{"read first copy hides the story", func(p *database.CountUnreadPostsForUserParams) {
+			p.CollapseDuplicates = true
+			err := f.q.MarkPostRead(context.Background(), database.MarkPostReadParams{
+				UserID: f.users["ada"].ID,
+				PostID: f.posts["release"].ID,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}, 3},
+	}
+	for _, tt := range tests {
+		params := base
+		if tt.change != nil {
+			tt.change(&params)
+		}
+		got, err := f.q.CountUnreadPostsForUser(context.Background(), params)
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBrowsePostsForUserRow(t *testing.T) {
+	f := browseFixture(t)
+	rows, err := f.q.BrowsePostsForUser(context.Background(), database.BrowsePostsForUserParams{
+		SortBy:    "published",
+		UserID:    f.users["ada"].ID,
+		FeedIds:   []uuid.UUID{f.feeds["go"].ID},
+		PostLimit: 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("got %d rows, want 1", len(rows))
+	}
+	row := rows[0]
+	if f.names[row.ID] != "release" || row.FeedName != "go" || row.FeedUrl != f.feeds["go"].Url {
+		t.Errorf("row = %s in %s (%s), want release in go", f.names[row.ID], row.FeedName, row.FeedUrl)
+	}
+	if !row.SortAt.Equal(*at(2)) {
+		t.Errorf("sort_at = %v, want %v", row.SortAt, *at(2))
+	}
+	if !slices.Equal(row.AlsoIn, []string{"rust"}) {
+		t.Errorf("also_in = %v, want [rust]", row.AlsoIn)
+	}
+	if !row.PublishedAt.Valid || !row.PublishedAt.Time.Equal(*at(2)) {
+		t.Errorf("published_at = %v, want %v", row.PublishedAt, *at(2))
+	}
+}
+
+func TestListExpiredPosts(t *testing.T) {
+	f := newFixture(t)
+	ctx := context.Background()
+	f.user("ada")
+	f.user("bob")
+	f.feed("old")
+	f.feed("lonely")
+	f.follow("ada", "old")
+	f.follow("bob", "old")
+	f.post(testPost{name: "read", feed: "old", title: "Read by everyone", published: at(0), fetched: *at(0)})
+	f.post(testPost{name: "unread", feed: "old", title: "Unread by bob", published: at(1), fetched: *at(1)})
+	f.post(testPost{name: "starred", feed: "old", title: "Read and starred", fetched: *at(2)})
+	f.post(testPost{name: "orphan", feed: "lonely", title: "Nobody follows this", published: at(0), fetched: *at(0)})
+	for user, posts := range map[string][]string{
+		"ada": {"read", "unread", "starred"},
+		"bob": {"read", "starred"},
+	} {
+		for _, post := range posts {
+			err := f.q.MarkPostRead(ctx, database.MarkPostReadParams{UserID: f.users[user].ID, PostID: f.posts[post].ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	err := f.q.SetPostStarred(ctx, database.SetPostStarredParams{UserID: f.users["ada"].ID, PostID: f.posts["starred"].ID, Starred: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cutoff := func(hours float64) sql.NullTime { return sql.NullTime{Time: *at(hours), Valid: true} }
+	maxPosts := func(n int64) sql.NullInt64 { return sql.NullInt64{Int64: n, Valid: true} }
+	tests := []struct {
+		name     string
+		feed     string
+		cutoff   sql.NullTime
+		maxPosts sql.NullInt64
+		// want lists expired post names oldest first; a trailing ! marks
+		// protected posts.
+		want []string
+	}{
+		{"nothing to expire", "old", sql.NullTime{}, sql.NullInt64{}, nil},
+		{"by age", "old", cutoff(1.5), sql.NullInt64{}, []string{"read", "unread!"}},
+		{"age uses fetch time without a publish date", "old", cutoff(3), sql.NullInt64{}, []string{"read", "unread!", "starred!"}},
+		{"by count", "old", sql.NullTime{}, maxPosts(1), []string{"read", "unread!"}},
+		{"by age or count", "old", cutoff(0.5), maxPosts(2), []string{"read"}},
+		{"no followers means nobody is waiting", "lonely", cutoff(1), sql.NullInt64{}, []string{"orphan"}},
+	}
+	for _, tt := range tests {
+		rows, err := f.q.ListExpiredPosts(ctx, database.ListExpiredPostsParams{
+			FeedID:   f.feeds[tt.feed].ID,
+			Cutoff:   tt.cutoff,
+			MaxPosts: tt.maxPosts,
+		})
+		if err != nil {
+			t.Errorf("%s: %v", tt.name, err)
+			continue
+		}
+		var got []string
+		for _, row := range rows {
+			name := f.names[row.ID]
+			if row.Protected {
+				name += "!"
+			}
+			got = append(got, name)
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestCheckRulePattern(t *testing.T) {
+	q := newTestQueries(t)
+	for _, tt := range []struct {
+		matchType, pattern string
+		ok                 bool
+	}{
+		{"keyword", "(unbalanced", true},
+		{"regex", `^go\b`, true},
+		{"regex", "(unbalanced", false},
+		{"regex", `kube(?=r)`, false},
+	} {
+		_, err := q.CheckRulePattern(context.Background(), database.CheckRulePatternParams{
+			MatchType: tt.matchType,
+			Pattern:   tt.pattern,
+		})
+		if (err == nil) != tt.ok {
+			t.Errorf("CheckRulePattern(%s, %q) error = %v, want ok = %v", tt.matchType, tt.pattern, err, tt.ok)
+		}
+	}
+}
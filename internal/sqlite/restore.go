@@ -0,0 +1,120 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const getPostIDByFeedURL = `
+SELECT id FROM posts
+WHERE feed_id = ?1 AND url = ?2
+`
+
+func (q *Queries) GetPostIDByFeedURL(ctx context.Context, arg database.GetPostIDByFeedURLParams) (uuid.UUID, error) {
+	var id uuid.UUID
+	err := q.queryRow(ctx, getPostIDByFeedURL, arg.FeedID, arg.Url).Scan(&id)
+	return id, err
+}
+
+const getTag = `
+SELECT ` + tagColumns + ` FROM tags
+WHERE user_id = ?1 AND name = ?2
+`
+
+func (q *Queries) GetTag(ctx context.Context, arg database.GetTagParams) (database.Tag, error) {
+	return scanTag(q.queryRow(ctx, getTag, arg.UserID, arg.Name))
+}
+
+const restoreFeed = `
+INSERT INTO feeds (id, created_at, updated_at, name, url, added_by, last_fetched_at, retention_days, retention_max_posts)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)
+`
+
+func (q *Queries) RestoreFeed(ctx context.Context, arg database.RestoreFeedParams) error {
+	_, err := q.exec(ctx, restoreFeed,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.Url,
+		arg.AddedBy,
+		arg.LastFetchedAt,
+		arg.RetentionDays,
+		arg.RetentionMaxPosts,
+	)
+	return err
+}
+
+const restorePostRule = `
+INSERT INTO post_rules (id, created_at, updated_at, user_id, feed_id, field, match_type, pattern, action)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9)
+ON CONFLICT DO NOTHING
+`
+
+func (q *Queries) RestorePostRule(ctx context.Context, arg database.RestorePostRuleParams) (int64, error) {
+	return q.execRows(ctx, restorePostRule,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.FeedID,
+		arg.Field,
+		arg.MatchType,
+		arg.Pattern,
+		arg.Action,
+	)
+}
+
+const restorePostState = `
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at, starred_at)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+ON CONFLICT DO NOTHING
+`
+
+func (q *Queries) RestorePostState(ctx context.Context, arg database.RestorePostStateParams) (int64, error) {
+	return q.execRows(ctx, restorePostState,
+		arg.UserID,
+		arg.PostID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.ReadAt,
+		arg.StarredAt,
+	)
+}
+
+const restoreUser = `
+INSERT INTO users (id, created_at, updated_at, name, password_hash, deactivated_at, role)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, arg database.RestoreUserParams) error {
+	_, err := q.exec(ctx, restoreUser,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.PasswordHash,
+		arg.DeactivatedAt,
+		arg.Role,
+	)
+	return err
+}
+
+const restoreUserPreference = `
+INSERT INTO user_preferences (user_id, key, value, updated_at)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT DO NOTHING
+`
+
+func (q *Queries) RestoreUserPreference(ctx context.Context, arg database.RestoreUserPreferenceParams) (int64, error) {
+	return q.execRows(ctx, restoreUserPreference,
+		arg.UserID,
+		arg.Key,
+		arg.Value,
+		arg.UpdatedAt,
+	)
+}
@@ -0,0 +1,87 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const savedSearchColumns = `id, created_at, updated_at, user_id, name, query, feed_ids, tags, author, window_seconds`
+
+func scanSavedSearch(row scanner) (database.SavedSearch, error) {
+	var i database.SavedSearch
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+		&i.Query,
+		jsonList(&i.FeedIds),
+		jsonList(&i.Tags),
+		&i.Author,
+		&i.WindowSeconds,
+	)
+	return i, err
+}
+
+const createSavedSearch = `
+INSERT INTO saved_searches (id, created_at, updated_at, user_id, name, query, feed_ids, tags, author, window_seconds)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10)
+RETURNING ` + savedSearchColumns
+
+func (q *Queries) CreateSavedSearch(ctx context.Context, arg database.CreateSavedSearchParams) (database.SavedSearch, error) {
+	feedIDs, err := jsonArray(arg.FeedIds)
+	if err != nil {
+		return database.SavedSearch{}, err
+	}
+	tags, err := jsonArray(arg.Tags)
+	if err != nil {
+		return database.SavedSearch{}, err
+	}
+	row := q.queryRow(ctx, createSavedSearch,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.Name,
+		arg.Query,
+		feedIDs,
+		tags,
+		arg.Author,
+		arg.WindowSeconds,
+	)
+	return scanSavedSearch(row)
+}
+
+const deleteSavedSearch = `
+DELETE FROM saved_searches
+WHERE user_id = ?1 AND name = ?2
+`
+
+func (q *Queries) DeleteSavedSearch(ctx context.Context, arg database.DeleteSavedSearchParams) (int64, error) {
+	return q.execRows(ctx, deleteSavedSearch, arg.UserID, arg.Name)
+}
+
+const getSavedSearch = `
+SELECT ` + savedSearchColumns + ` FROM saved_searches
+WHERE user_id = ?1 AND name = ?2
+`
+
+func (q *Queries) GetSavedSearch(ctx context.Context, arg database.GetSavedSearchParams) (database.SavedSearch, error) {
+	return scanSavedSearch(q.queryRow(ctx, getSavedSearch, arg.UserID, arg.Name))
+}
+
+const getSavedSearchesForUser = `
+SELECT ` + savedSearchColumns + ` FROM saved_searches
+WHERE user_id = ?1
+ORDER BY name
+`
+
+func (q *Queries) GetSavedSearchesForUser(ctx context.Context, userID uuid.UUID) ([]database.SavedSearch, error) {
+	rows, err := q.query(ctx, getSavedSearchesForUser, userID)
+	return collect(rows, err, scanSavedSearch)
+}
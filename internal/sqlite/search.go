@@ -0,0 +1,52 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+)
+
+// searchPostsForUser ranks with fts_rank and cuts snippets from whichever
+// column matched best, where Postgres prefers the description.
+const searchPostsForUser = `
+SELECT posts.id, posts.title, posts.url, posts.published_at, posts.created_at, feeds.name AS feed_name,
+    fts_rank(matchinfo(posts_fts, 'pcx')) AS rank,
+    snippet(posts_fts, '*', '*', ' ... ', -1, 35) AS snippet,
+    ` + postHighlighted + ` AS highlighted
+FROM posts_fts
+JOIN posts ON posts.seq = posts_fts.docid
+JOIN feeds ON posts.feed_id = feeds.id
+JOIN feed_follows ON feeds.id = feed_follows.feed_id
+WHERE posts_fts MATCH ?1
+    AND feed_follows.user_id = ?2
+    AND NOT ` + postMuted + `
+    AND (?3 IS NULL OR posts.feed_id = ?3)
+ORDER BY rank DESC, posts.id DESC
+LIMIT ?4
+`
+
+func (q *Queries) SearchPostsForUser(ctx context.Context, arg database.SearchPostsForUserParams) ([]database.SearchPostsForUserRow, error) {
+	rows, err := q.query(ctx, searchPostsForUser,
+		ftsQuery(arg.Query),
+		arg.UserID,
+		arg.FeedID,
+		arg.PostLimit,
+	)
+	return collect(rows, err, func(row scanner) (database.SearchPostsForUserRow, error) {
+		var i database.SearchPostsForUserRow
+		err := row.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+			&i.CreatedAt,
+			&i.FeedName,
+			&i.Rank,
+			&i.Snippet,
+			&i.Highlighted,
+		)
+		return i, err
+	})
+}
@@ -0,0 +1,53 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const createSession = `
+INSERT INTO sessions (token_hash, user_id, created_at, expires_at)
+VALUES (?1, ?2, ?3, ?4)
+`
+
+func (q *Queries) CreateSession(ctx context.Context, arg database.CreateSessionParams) error {
+	_, err := q.exec(ctx, createSession,
+		arg.TokenHash,
+		arg.UserID,
+		arg.CreatedAt,
+		arg.ExpiresAt,
+	)
+	return err
+}
+
+const deleteSession = `
+DELETE FROM sessions WHERE token_hash = ?1
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, tokenHash string) error {
+	_, err := q.exec(ctx, deleteSession, tokenHash)
+	return err
+}
+
+const deleteSessionsForUser = `
+DELETE FROM sessions WHERE user_id = ?1
+`
+
+func (q *Queries) DeleteSessionsForUser(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.exec(ctx, deleteSessionsForUser, userID)
+	return err
+}
+
+const getUserBySession = `
+SELECT ` + userColumns + ` FROM sessions
+JOIN users ON sessions.user_id = users.id
+WHERE sessions.token_hash = ?1 AND sessions.expires_at > now() AND users.deactivated_at IS NULL
+`
+
+func (q *Queries) GetUserBySession(ctx context.Context, tokenHash string) (database.User, error) {
+	return scanUser(q.queryRow(ctx, getUserBySession, tokenHash))
+}
@@ -0,0 +1,113 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+)
+
+const listFeedFollowTags = `
+SELECT feed_follow_id, tag_id, created_at FROM feed_follow_tags
+ORDER BY feed_follow_id, tag_id
+`
+
+func (q *Queries) ListFeedFollowTags(ctx context.Context) ([]database.FeedFollowTag, error) {
+	rows, err := q.query(ctx, listFeedFollowTags)
+	return collect(rows, err, func(row scanner) (database.FeedFollowTag, error) {
+		var i database.FeedFollowTag
+		err := row.Scan(&i.FeedFollowID, &i.TagID, &i.CreatedAt)
+		return i, err
+	})
+}
+
+const listFeedFollows = `
+SELECT ` + feedFollowColumns + ` FROM feed_follows
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListFeedFollows(ctx context.Context) ([]database.FeedFollow, error) {
+	rows, err := q.query(ctx, listFeedFollows)
+	return collect(rows, err, scanFeedFollow)
+}
+
+const listPostRules = `
+SELECT ` + postRuleColumns + ` FROM post_rules
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListPostRules(ctx context.Context) ([]database.PostRule, error) {
+	rows, err := q.query(ctx, listPostRules)
+	return collect(rows, err, scanPostRule)
+}
+
+const listPostStates = `
+SELECT ` + postStateColumns + ` FROM post_states
+ORDER BY user_id, post_id
+`
+
+func (q *Queries) ListPostStates(ctx context.Context) ([]database.PostState, error) {
+	rows, err := q.query(ctx, listPostStates)
+	return collect(rows, err, scanPostState)
+}
+
+const listPosts = `
+SELECT ` + postColumns + `
+FROM posts
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListPosts(ctx context.Context) ([]database.ListPostsRow, error) {
+	rows, err := q.query(ctx, listPosts)
+	return collect(rows, err, func(row scanner) (database.ListPostsRow, error) {
+		var i database.ListPostsRow
+		err := row.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Author,
+			&i.Content,
+			jsonList(&i.Categories),
+			&i.CanonicalUrl,
+			&i.Fingerprint,
+			&i.ClusterID,
+		)
+		return i, err
+	})
+}
+
+const listSavedSearches = `
+SELECT ` + savedSearchColumns + ` FROM saved_searches
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListSavedSearches(ctx context.Context) ([]database.SavedSearch, error) {
+	rows, err := q.query(ctx, listSavedSearches)
+	return collect(rows, err, scanSavedSearch)
+}
+
+const listTags = `
+SELECT ` + tagColumns + ` FROM tags
+ORDER BY created_at, id
+`
+
+func (q *Queries) ListTags(ctx context.Context) ([]database.Tag, error) {
+	rows, err := q.query(ctx, listTags)
+	return collect(rows, err, scanTag)
+}
+
+const listUserPreferences = `
+SELECT ` + userPreferenceColumns + ` FROM user_preferences
+ORDER BY user_id, key
+`
+
+func (q *Queries) ListUserPreferences(ctx context.Context) ([]database.UserPreference, error) {
+	rows, err := q.query(ctx, listUserPreferences)
+	return collect(rows, err, scanUserPreference)
+}
@@ -0,0 +1,90 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const tagColumns = `id, created_at, updated_at, user_id, name`
+
+func scanTag(row scanner) (database.Tag, error) {
+	var i database.Tag
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.UserID,
+		&i.Name,
+	)
+	return i, err
+}
+
+const addFollowTag = `
+INSERT INTO feed_follow_tags (feed_follow_id, tag_id, created_at)
+VALUES (?1, ?2, ?3)
+ON CONFLICT DO NOTHING
+`
+
+func (q *Queries) AddFollowTag(ctx context.Context, arg database.AddFollowTagParams) (int64, error) {
+	return q.execRows(ctx, addFollowTag, arg.FeedFollowID, arg.TagID, arg.CreatedAt)
+}
+
+const deleteUnusedTags = `
+DELETE FROM tags
+WHERE user_id = ?1
+    AND NOT EXISTS (SELECT 1 FROM feed_follow_tags WHERE feed_follow_tags.tag_id = tags.id)
+`
+
+func (q *Queries) DeleteUnusedTags(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.exec(ctx, deleteUnusedTags, userID)
+	return err
+}
+
+const getFollowTagsForUser = `
+SELECT feed_follow_tags.feed_follow_id, tags.name
+FROM feed_follow_tags
+JOIN tags ON feed_follow_tags.tag_id = tags.id
+WHERE tags.user_id = ?1
+ORDER BY tags.name
+`
+
+func (q *Queries) GetFollowTagsForUser(ctx context.Context, userID uuid.UUID) ([]database.GetFollowTagsForUserRow, error) {
+	rows, err := q.query(ctx, getFollowTagsForUser, userID)
+	return collect(rows, err, func(row scanner) (database.GetFollowTagsForUserRow, error) {
+		var i database.GetFollowTagsForUserRow
+		err := row.Scan(&i.FeedFollowID, &i.Name)
+		return i, err
+	})
+}
+
+// SQLite has no DELETE ... USING.
+const removeFollowTag = `
+DELETE FROM feed_follow_tags
+WHERE feed_follow_id = ?1
+    AND tag_id IN (SELECT tags.id FROM tags WHERE tags.name = ?2)
+`
+
+func (q *Queries) RemoveFollowTag(ctx context.Context, arg database.RemoveFollowTagParams) (int64, error) {
+	return q.execRows(ctx, removeFollowTag, arg.FeedFollowID, arg.Name)
+}
+
+const upsertTag = `
+INSERT INTO tags (id, created_at, updated_at, user_id, name)
+VALUES (?1, ?2, ?3, ?4, ?5)
+ON CONFLICT (user_id, name) DO UPDATE SET updated_at = excluded.updated_at
+RETURNING ` + tagColumns
+
+func (q *Queries) UpsertTag(ctx context.Context, arg database.UpsertTagParams) (database.Tag, error) {
+	row := q.queryRow(ctx, upsertTag,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.UserID,
+		arg.Name,
+	)
+	return scanTag(row)
+}
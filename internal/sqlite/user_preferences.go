@@ -0,0 +1,59 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const userPreferenceColumns = `user_id, key, value, updated_at`
+
+func scanUserPreference(row scanner) (database.UserPreference, error) {
+	var i database.UserPreference
+	err := row.Scan(
+		&i.UserID,
+		&i.Key,
+		&i.Value,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const deleteUserPreference = `
+DELETE FROM user_preferences
+WHERE user_id = ?1 AND key = ?2
+`
+
+func (q *Queries) DeleteUserPreference(ctx context.Context, arg database.DeleteUserPreferenceParams) (int64, error) {
+	return q.execRows(ctx, deleteUserPreference, arg.UserID, arg.Key)
+}
+
+const getUserPreferences = `
+SELECT ` + userPreferenceColumns + ` FROM user_preferences
+WHERE user_id = ?1
+ORDER BY key
+`
+
+func (q *Queries) GetUserPreferences(ctx context.Context, userID uuid.UUID) ([]database.UserPreference, error) {
+	rows, err := q.query(ctx, getUserPreferences, userID)
+	return collect(rows, err, scanUserPreference)
+}
+
+const setUserPreference = `
+INSERT INTO user_preferences (user_id, key, value, updated_at)
+VALUES (?1, ?2, ?3, ?4)
+ON CONFLICT (user_id, key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at
+`
+
+func (q *Queries) SetUserPreference(ctx context.Context, arg database.SetUserPreferenceParams) error {
+	_, err := q.exec(ctx, setUserPreference,
+		arg.UserID,
+		arg.Key,
+		arg.Value,
+		arg.UpdatedAt,
+	)
+	return err
+}
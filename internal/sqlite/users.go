@@ -0,0 +1,149 @@
+//go:build cgo
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/google/uuid"
+)
+
+const userColumns = `users.id, users.created_at, users.updated_at, users.name, users.password_hash, users.deactivated_at, users.role`
+
+func scanUser(row scanner) (database.User, error) {
+	var i database.User
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.PasswordHash,
+		&i.DeactivatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const countActiveAdmins = `
+SELECT COUNT(*) FROM users
+WHERE role = 'admin' AND deactivated_at IS NULL
+`
+
+func (q *Queries) CountActiveAdmins(ctx context.Context) (int64, error) {
+	var count int64
+	err := q.queryRow(ctx, countActiveAdmins).Scan(&count)
+	return count, err
+}
+
+const countUsers = `
+SELECT COUNT(*) FROM users
+`
+
+func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
+	var count int64
+	err := q.queryRow(ctx, countUsers).Scan(&count)
+	return count, err
+}
+
+const countUsersWithPassword = `
+SELECT COUNT(*) FROM users
+WHERE password_hash IS NOT NULL AND deactivated_at IS NULL
+`
+
+func (q *Queries) CountUsersWithPassword(ctx context.Context) (int64, error) {
+	var count int64
+	err := q.queryRow(ctx, countUsersWithPassword).Scan(&count)
+	return count, err
+}
+
+const createUser = `
+INSERT INTO users (id, created_at, updated_at, name, password_hash, role)
+VALUES (?1, ?2, ?3, ?4, ?5, ?6)
+RETURNING ` + userColumns
+
+func (q *Queries) CreateUser(ctx context.Context, arg database.CreateUserParams) (database.User, error) {
+	row := q.queryRow(ctx, createUser,
+		arg.ID,
+		arg.CreatedAt,
+		arg.UpdatedAt,
+		arg.Name,
+		arg.PasswordHash,
+		arg.Role,
+	)
+	return scanUser(row)
+}
+
+const deleteUser = `
+DELETE FROM users WHERE id = ?1
+`
+
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	return q.execRows(ctx, deleteUser, id)
+}
+
+const deleteUsers = `
+DELETE FROM users
+`
+
+func (q *Queries) DeleteUsers(ctx context.Context) error {
+	_, err := q.exec(ctx, deleteUsers)
+	return err
+}
+
+const getUser = `
+SELECT ` + userColumns + ` FROM users WHERE name = ?1
+`
+
+func (q *Queries) GetUser(ctx context.Context, name string) (database.User, error) {
+	return scanUser(q.queryRow(ctx, getUser, name))
+}
+
+const getUsers = `
+SELECT ` + userColumns + ` FROM users
+`
+
+func (q *Queries) GetUsers(ctx context.Context) ([]database.User, error) {
+	rows, err := q.query(ctx, getUsers)
+	return collect(rows, err, scanUser)
+}
+
+const renameUser = `
+UPDATE users SET name = ?2, updated_at = now()
+WHERE id = ?1
+`
+
+func (q *Queries) RenameUser(ctx context.Context, arg database.RenameUserParams) error {
+	_, err := q.exec(ctx, renameUser, arg.ID, arg.Name)
+	return err
+}
+
+const setUserDeactivated = `
+UPDATE users SET deactivated_at = ?2, updated_at = now()
+WHERE id = ?1
+`
+
+func (q *Queries) SetUserDeactivated(ctx context.Context, arg database.SetUserDeactivatedParams) error {
+	_, err := q.exec(ctx, setUserDeactivated, arg.ID, arg.DeactivatedAt)
+	return err
+}
+
+const setUserPassword = `
+UPDATE users SET password_hash = ?2, updated_at = now()
+WHERE id = ?1
+`
+
+func (q *Queries) SetUserPassword(ctx context.Context, arg database.SetUserPasswordParams) error {
+	_, err := q.exec(ctx, setUserPassword, arg.ID, arg.PasswordHash)
+	return err
+}
+
+const setUserRole = `
+UPDATE users SET role = ?2, updated_at = now()
+WHERE id = ?1
+`
+
+func (q *Queries) SetUserRole(ctx context.Context, arg database.SetUserRoleParams) error {
+	_, err := q.exec(ctx, setUserRole, arg.ID, arg.Role)
+	return err
+}
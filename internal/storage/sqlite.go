@@ -0,0 +1,43 @@
+//go:build cgo
+
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/sqlite"
+	"github.com/mattn/go-sqlite3"
+)
+
+func openSQLite(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	}
+	// The file holds password hashes and sessions, so create it private
+	// rather than with SQLite's default 0644.
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	conn, err := sql.Open(sqlite.DriverName, sqlite.DSN(path))
+	if err != nil {
+		return nil, err
+	}
+	queries := sqlite.New(conn)
+	return newDB(conn, SQLite, queries, func(tx *sql.Tx) database.Querier { return queries.WithTx(tx) })
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique ||
+			sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
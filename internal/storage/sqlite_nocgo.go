@@ -0,0 +1,14 @@
+//go:build !cgo
+
+package storage
+
+import "errors"
+
+// openSQLite fails in a build without cgo, which the SQLite driver needs.
+func openSQLite(path string) (*DB, error) {
+	return nil, errors.New("this gator was built without cgo and can't open SQLite databases; set db_url to a Postgres URL")
+}
+
+func isSQLiteUniqueViolation(err error) bool {
+	return false
+}
@@ -0,0 +1,152 @@
+// Package storage opens the database a db_url points at. Postgres URLs and
+// key=value DSNs go to a Postgres server; sqlite: URLs name a local SQLite
+// file, which needs no server and is what a new install uses. SQLite needs
+// cgo; a build without it only opens Postgres.
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/migrate"
+	pgschema "github.com/Grumpster-Dev/gator/sql/schema"
+	sqliteschema "github.com/Grumpster-Dev/gator/sql/sqlite/schema"
+	"github.com/lib/pq"
+)
+
+// Engine names a supported database.
+type Engine string
+
+const (
+	Postgres Engine = "postgres"
+	SQLite   Engine = "sqlite"
+)
+
+// Store is everything gator reads and writes, whichever engine holds it.
+type Store interface {
+	database.Querier
+	// InTx calls fn with a Store whose queries all run in one transaction,
+	// committed if fn returns nil and rolled back otherwise. Inside a
+	// transaction, InTx joins it.
+	InTx(ctx context.Context, fn func(Store) error) error
+}
+
+// DB is an open database with the migrations for its engine.
+type DB struct {
+	Store
+	Engine   Engine
+	Migrator *migrate.Migrator
+	conn     *sql.DB
+}
+
+// Open connects to the database at dbURL. A SQLite file and its directory
+// are created if they don't exist yet.
+func Open(dbURL string) (*DB, error) {
+	if path, ok := SQLitePath(dbURL); ok {
+		return openSQLite(path)
+	}
+	conn, err := sql.Open("postgres", dbURL)
+	if err != nil {
+		return nil, err
+	}
+	queries := database.New(conn)
+	return newDB(conn, Postgres, queries, func(tx *sql.Tx) database.Querier { return queries.WithTx(tx) })
+}
+
+func newDB(conn *sql.DB, engine Engine, queries database.Querier, withTx func(*sql.Tx) database.Querier) (*DB, error) {
+	dialect, schema := migrate.Postgres, fs.FS(pgschema.FS)
+	if engine == SQLite {
+		dialect, schema = migrate.SQLite, sqliteschema.FS
+	}
+	migrator, err := migrate.NewFromFS(conn, dialect, schema)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("couldn't load migrations: %w", err)
+	}
+	return &DB{
+		Store:    &store{Querier: queries, conn: conn, withTx: withTx},
+		Engine:   engine,
+		Migrator: migrator,
+		conn:     conn,
+	}, nil
+}
+
+// Ping checks that the database can be reached.
+func (db *DB) Ping(ctx context.Context) error {
+	return db.conn.PingContext(ctx)
+}
+
+func (db *DB) Close() error {
+	return db.conn.Close()
+}
+
+type store struct {
+	database.Querier
+	// conn is nil once the store is bound to a transaction.
+	conn   *sql.DB
+	withTx func(*sql.Tx) database.Querier
+}
+
+func (s *store) InTx(ctx context.Context, fn func(Store) error) error {
+	if s.conn == nil {
+		return fn(s)
+	}
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	if err := fn(&store{Querier: s.withTx(tx), withTx: s.withTx}); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// SQLitePath returns the file a sqlite:PATH or sqlite://PATH URL names,
+// with a leading ~ expanded to the home directory.
+func SQLitePath(dbURL string) (string, bool) {
+	rest, ok := strings.CutPrefix(dbURL, "sqlite:")
+	if !ok {
+		return "", false
+	}
+	rest = strings.TrimPrefix(rest, "//")
+	if home, err := os.UserHomeDir(); err == nil {
+		if rest == "~" {
+			rest = home
+		} else if after, ok := strings.CutPrefix(rest, "~/"); ok {
+			rest = filepath.Join(home, after)
+		}
+	}
+	return rest, rest != ""
+}
+
+// DefaultURL is the SQLite database used while db_url is unset:
+// gator/gator.db under $XDG_DATA_HOME, or ~/.local/share.
+func DefaultURL() (string, error) {
+	dataDir := os.Getenv("XDG_DATA_HOME")
+	if dataDir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dataDir = filepath.Join(home, ".local", "share")
+	}
+	return "sqlite:" + filepath.Join(dataDir, "gator", "gator.db"), nil
+}
+
+// IsUniqueViolation reports whether err is a unique constraint failure on
+// either engine.
+func IsUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return pqErr.Code == "23505"
+	}
+	return isSQLiteUniqueViolation(err)
+}
@@ -0,0 +1,399 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/htmltext"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/google/uuid"
+)
+
+const postsPerFeed = 200
+
+type pane int
+
+const (
+	feedPane pane = iota
+	postPane
+	articlePane
+)
+
+var (
+	paneStyle   = lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(lipgloss.Color("240"))
+	focusStyle  = paneStyle.BorderForeground(lipgloss.Color("63"))
+	cursorStyle = lipgloss.NewStyle().Reverse(true)
+	unreadStyle = lipgloss.NewStyle().Bold(true)
+	titleStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("63"))
+	dimStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+)
+
+type feedsLoadedMsg struct {
+	feeds []database.GetFeedsWithUnreadCountsRow
+	err   error
+}
+
+type postsLoadedMsg struct {
+	feedID uuid.UUID
+	posts  []database.GetPostsWithStateForFeedRow
+	err    error
+}
+
+type stateChangedMsg struct {
+	err error
+}
+
+type tickMsg time.Time
+
+type model struct {
+	db      database.Querier
+	user    database.User
+	refresh time.Duration
+	loc     *time.Location
+
+	feeds      []database.GetFeedsWithUnreadCountsRow
+	posts      []database.GetPostsWithStateForFeedRow
+	feedCursor int
+	postCursor int
+	scroll     int
+	focus      pane
+
+	width  int
+	height int
+	status string
+}
+
+// Run starts the full-screen reader for user and blocks until they quit.
+// Feeds and posts are reloaded every refresh interval, and dates are shown
+// in loc.
+func Run(db database.Querier, user database.User, refresh time.Duration, loc *time.Location) error {
+	m := model{
+		db:      db,
+		user:    user,
+		refresh: refresh,
+		loc:     loc,
+		status:  "loading...",
+	}
+	_, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	return err
+}
+
+func (m model) Init() tea.Cmd {
+	return tea.Batch(m.loadFeeds(), m.tick())
+}
+
+func (m model) tick() tea.Cmd {
+	return tea.Tick(m.refresh, func(t time.Time) tea.Msg {
+		return tickMsg(t)
+	})
+}
+
+func (m model) loadFeeds() tea.Cmd {
+	return func() tea.Msg {
+		feeds, err := m.db.GetFeedsWithUnreadCounts(context.Background(), m.user.ID)
+		return feedsLoadedMsg{feeds: feeds, err: err}
+	}
+}
+
+func (m model) loadPosts(feedID uuid.UUID) tea.Cmd {
+	return func() tea.Msg {
+		posts, err := m.db.GetPostsWithStateForFeed(context.Background(), database.GetPostsWithStateForFeedParams{
+			UserID:    m.user.ID,
+			FeedID:    feedID,
+			PostLimit: postsPerFeed,
+		})
+		return postsLoadedMsg{feedID: feedID, posts: posts, err: err}
+	}
+}
+
+func (m model) selectedFeed() (database.GetFeedsWithUnreadCountsRow, bool) {
+	if m.feedCursor < 0 || m.feedCursor >= len(m.feeds) {
+		return database.GetFeedsWithUnreadCountsRow{}, false
+	}
+	return m.feeds[m.feedCursor], true
+}
+
+func (m model) selectedPost() (database.GetPostsWithStateForFeedRow, bool) {
+	if m.postCursor < 0 || m.postCursor >= len(m.posts) {
+		return database.GetPostsWithStateForFeedRow{}, false
+	}
+	return m.posts[m.postCursor], true
+}
+
+func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		return m, nil
+
+	case tickMsg:
+		return m, tea.Batch(m.loadFeeds(), m.tick())
+
+	case feedsLoadedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("failed to load feeds: %v", msg.err)
+			return m, nil
+		}
+		// Keep the same feed selected across refreshes.
+		selected, hadSelection := m.selectedFeed()
+		m.feeds = msg.feeds
+		m.feedCursor = 0
+		if hadSelection {
+			for i, feed := range m.feeds {
+				if feed.ID == selected.ID {
+					m.feedCursor = i
+					break
+				}
+			}
+		}
+		m.status = fmt.Sprintf("updated %s", time.Now().In(m.loc).Format(time.TimeOnly))
+		if feed, ok := m.selectedFeed(); ok {
+			return m, m.loadPosts(feed.ID)
+		}
+		m.posts = nil
+		return m, nil
+
+	case postsLoadedMsg:
+		feed, ok := m.selectedFeed()
+		if !ok || feed.ID != msg.feedID {
+			return m, nil
+		}
+		if msg.err != nil {
+			m.status = fmt.Sprintf("failed to load posts: %v", msg.err)
+			return m, nil
+		}
+		selected, hadSelection := m.selectedPost()
+		m.posts = msg.posts
+		m.postCursor = 0
+		if hadSelection {
+			for i, post := range m.posts {
+				if post.ID == selected.ID {
+					m.postCursor = i
+					break
+				}
+			}
+		}
+		return m, nil
+
+	case stateChangedMsg:
+		if msg.err != nil {
+			m.status = fmt.Sprintf("failed to update post: %v", msg.err)
+			return m, nil
+		}
+		return m, m.loadFeeds()
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+func (m model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "ctrl+c":
+		return m, tea.Quit
+	case "tab", "l", "right":
+		if m.focus < articlePane {
+			m.focus++
+		}
+	case "shift+tab", "h", "left":
+		if m.focus > feedPane {
+			m.focus--
+		}
+	case "j", "down":
+		return m.move(1)
+	case "k", "up":
+		return m.move(-1)
+	case "enter":
+		switch m.focus {
+		case feedPane:
+			m.focus = postPane
+		case postPane:
+			post, ok := m.selectedPost()
+			if !ok {
+				return m, nil
+			}
+			m.focus = articlePane
+			m.scroll = 0
+			if !post.ReadAt.Valid {
+				return m, m.setRead(post.ID, true)
+			}
+		}
+	case "u":
+		if post, ok := m.selectedPost(); ok {
+			return m, m.setRead(post.ID, !post.ReadAt.Valid)
+		}
+	case "s":
+		if post, ok := m.selectedPost(); ok {
+			return m, m.setStarred(post.ID, !post.StarredAt.Valid)
+		}
+	case "r":
+		m.status = "refreshing..."
+		return m, m.loadFeeds()
+	}
+	return m, nil
+}
+
+func (m model) move(delta int) (tea.Model, tea.Cmd) {
+	switch m.focus {
+	case feedPane:
+		next := clamp(m.feedCursor+delta, 0, len(m.feeds)-1)
+		if next == m.feedCursor {
+			return m, nil
+		}
+		m.feedCursor = next
+		m.posts = nil
+		m.postCursor = 0
+		m.scroll = 0
+		return m, m.loadPosts(m.feeds[next].ID)
+	case postPane:
+		m.postCursor = clamp(m.postCursor+delta, 0, len(m.posts)-1)
+		m.scroll = 0
+	case articlePane:
+		m.scroll = max(m.scroll+delta, 0)
+	}
+	return m, nil
+}
+
+func (m model) setRead(postID uuid.UUID, read bool) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if read {
+			err = m.db.MarkPostRead(context.Background(), database.MarkPostReadParams{UserID: m.user.ID, PostID: postID})
+		} else {
+			err = m.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{UserID: m.user.ID, PostID: postID})
+		}
+		return stateChangedMsg{err: err}
+	}
+}
+
+func (m model) setStarred(postID uuid.UUID, starred bool) tea.Cmd {
+	return func() tea.Msg {
+		err := m.db.SetPostStarred(context.Background(), database.SetPostStarredParams{
+			UserID:  m.user.ID,
+			PostID:  postID,
+			Starred: starred,
+		})
+		return stateChangedMsg{err: err}
+	}
+}
+
+func (m model) View() string {
+	if m.width == 0 || m.height == 0 {
+		return m.status
+	}
+
+	// Each pane loses two columns and two rows to its border, and the
+	// status line takes the last row of the screen.
+	innerHeight := max(m.height-3, 1)
+	feedWidth := max(m.width/4-2, 10)
+	postWidth := max(m.width*3/8-2, 10)
+	articleWidth := max(m.width-feedWidth-postWidth-6, 10)
+
+	feedLines := make([]string, len(m.feeds))
+	for i, feed := range m.feeds {
+		line := fmt.Sprintf("%s (%d)", feed.Name, feed.UnreadCount)
+		if feed.UnreadCount > 0 {
+			line = unreadStyle.Render(line)
+		}
+		feedLines[i] = line
+	}
+
+	postLines := make([]string, len(m.posts))
+	for i, post := range m.posts {
+		marker := " "
+		if post.StarredAt.Valid {
+			marker = "*"
+		}
+		line := marker + " " + post.Title
+		if !post.ReadAt.Valid {
+			line = unreadStyle.Render(line)
+		}
+		postLines[i] = line
+	}
+
+	panes := lipgloss.JoinHorizontal(lipgloss.Top,
+		m.renderList(feedPane, feedLines, m.feedCursor, feedWidth, innerHeight),
+		m.renderList(postPane, postLines, m.postCursor, postWidth, innerHeight),
+		m.renderArticle(articleWidth, innerHeight),
+	)
+	help := "tab/h/l: switch pane  j/k: move  enter: open  u: read/unread  s: star  r: refresh  q: quit"
+	return panes + "\n" + dimStyle.Render(truncate(m.status+"  "+help, m.width))
+}
+
+func (m model) renderList(p pane, lines []string, cursor, width, height int) string {
+	// Scroll the list just far enough to keep the cursor visible.
+	start := 0
+	if cursor >= height {
+		start = cursor - height + 1
+	}
+	var b strings.Builder
+	for i := start; i < len(lines) && i < start+height; i++ {
+		line := truncate(lines[i], width)
+		if i == cursor {
+			line = cursorStyle.Render(line)
+		}
+		b.WriteString(line)
+		if i < start+height-1 {
+			b.WriteString("\n")
+		}
+	}
+	return m.paneStyle(p).Width(width).Height(height).Render(b.String())
+}
+
+func (m model) renderArticle(width, height int) string {
+	post, ok := m.selectedPost()
+	if !ok {
+		return m.paneStyle(articlePane).Width(width).Height(height).Render("")
+	}
+
+	date := post.CreatedAt
+	if post.PublishedAt.Valid {
+		date = post.PublishedAt.Time
+	}
+	byline := date.In(m.loc).Format(time.DateTime)
+	if post.Author.Valid {
+		byline = post.Author.String + ", " + byline
+	}
+	body := post.Content.String
+	if body == "" {
+		body = post.Description.String
+	}
+
+	wrap := lipgloss.NewStyle().Width(width)
+	text := strings.Join([]string{
+		titleStyle.Render(wrap.Render(post.Title)),
+		dimStyle.Render(wrap.Render(byline)),
+		dimStyle.Render(wrap.Render(post.Url)),
+		"",
+		wrap.Render(htmltext.PlainText(body)),
+	}, "\n")
+
+	lines := strings.Split(text, "\n")
+	start := min(m.scroll, max(len(lines)-height, 0))
+	end := min(start+height, len(lines))
+	return m.paneStyle(articlePane).Width(width).Height(height).Render(strings.Join(lines[start:end], "\n"))
+}
+
+func (m model) paneStyle(p pane) lipgloss.Style {
+	if m.focus == p {
+		return focusStyle
+	}
+	return paneStyle
+}
+
+func truncate(s string, width int) string {
+	return lipgloss.NewStyle().MaxWidth(width).Render(s)
+}
+
+func clamp(v, lo, hi int) int {
+	if hi < lo {
+		return lo
+	}
+	return min(max(v, lo), hi)
+}
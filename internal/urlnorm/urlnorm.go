@@ -0,0 +1,76 @@
+package urlnorm
+
+import (
+	"net/url"
+	"strings"
+)
+
+// DefaultStripParams are query parameters that only track where a click
+// came from. A trailing "*" matches any parameter with that prefix.
+var DefaultStripParams = []string{
+	"utm_*",
+	"fbclid",
+	"gclid",
+	"dclid",
+	"msclkid",
+	"mc_cid",
+	"mc_eid",
+	"_hsenc",
+	"_hsmi",
+	"ref",
+	"ref_src",
+}
+
+// Normalizer rewrites URLs into a canonical form so that variants of the
+// same address compare equal.
+type Normalizer struct {
+	StripParams []string
+}
+
+// Default strips DefaultStripParams.
+var Default = Normalizer{StripParams: DefaultStripParams}
+
+// Normalize lowercases the scheme and host, drops default ports and the
+// fragment, removes tracking parameters and sorts what is left of the query.
+func (n Normalizer) Normalize(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", err
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if port := u.Port(); (u.Scheme == "http" && port == "80") || (u.Scheme == "https" && port == "443") {
+		u.Host = u.Hostname()
+	}
+	if u.Path == "" && u.Host != "" {
+		u.Path = "/"
+	}
+	u.Fragment = ""
+	u.RawFragment = ""
+
+	if u.RawQuery != "" {
+		query := u.Query()
+		for name := range query {
+			if n.strip(name) {
+				query.Del(name)
+			}
+		}
+		u.RawQuery = query.Encode()
+	}
+	return u.String(), nil
+}
+
+func (n Normalizer) strip(param string) bool {
+	param = strings.ToLower(param)
+	for _, pattern := range n.StripParams {
+		pattern = strings.ToLower(pattern)
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(param, prefix) {
+				return true
+			}
+		} else if param == pattern {
+			return true
+		}
+	}
+	return false
+}
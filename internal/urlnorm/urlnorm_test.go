@@ -0,0 +1,56 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"HTTPS://Example.COM", "https://example.com/"},
+		{"http://example.com:80/a", "http://example.com/a"},
+		{"https://example.com:443/a", "https://example.com/a"},
+		{"https://example.com:8443/a", "https://example.com:8443/a"},
+		{"https://example.com/a#section", "https://example.com/a"},
+		{"  https://example.com/a  ", "https://example.com/a"},
+		{"https://example.com/a?utm_source=rss&utm_medium=feed", "https://example.com/a"},
+		{"https://example.com/a?b=2&UTM_Campaign=x&a=1&fbclid=y", "https://example.com/a?a=1&b=2"},
+		{"https://example.com/a?reference=1&ref=2", "https://example.com/a?reference=1"},
+		{"https://example.com/Case/Path", "https://example.com/Case/Path"},
+	}
+	for _, tt := range tests {
+		got, err := Default.Normalize(tt.in)
+		if err != nil {
+			t.Errorf("Normalize(%q): %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeStripParams(t *testing.T) {
+	keepAll := Normalizer{StripParams: []string{}}
+	got, err := keepAll.Normalize("https://example.com/a?utm_source=rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "https://example.com/a?utm_source=rss" {
+		t.Errorf("an empty strip list removed a parameter: %q", got)
+	}
+
+	custom := Normalizer{StripParams: []string{"session*", "x"}}
+	got, err = custom.Normalize("https://example.com/a?SessionID=1&x=2&utm_source=rss")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "https://example.com/a?utm_source=rss" {
+		t.Errorf("custom strip list = %q", got)
+	}
+}
+
+func TestNormalizeInvalid(t *testing.T) {
+	if _, err := Default.Normalize("http://exa mple.com/%zz"); err == nil {
+		t.Error("Normalize accepted an invalid URL")
+	}
+}
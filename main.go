@@ -1,33 +1,75 @@
 package main
 
 import (
-	"database/sql"
 	"fmt"
 	"os"
 
 	"github.com/Grumpster-Dev/gator/internal/config"
-	"github.com/Grumpster-Dev/gator/internal/database"
-	_ "github.com/lib/pq"
+	"github.com/Grumpster-Dev/gator/internal/storage"
 )
 
 func main() {
-	cfg, err := config.Read()
+	configFlags, args, err := extractGlobalFlags(os.Args[1:], "config", "profile")
 	if err != nil {
-		fmt.Println("Error reading config:", err)
-		return
+		fmt.Println("Error:", err)
+		os.Exit(1)
 	}
-
-	db, err := sql.Open("postgres", cfg.DBURL)
+	output, args, err := extractOutputOptions(args)
 	if err != nil {
-		fmt.Printf("Error connecting to database: %v\n", err)
+		fmt.Println("Error:", err)
 		os.Exit(1)
 	}
 
-	dbQueries := database.New(db)
+	cfg, err := config.Read(config.Options{
+		Path:          configFlags["config"],
+		Profile:       configFlags["profile"],
+		CreateProfile: len(args) > 0 && args[0] == "config",
+	})
+	if err != nil {
+		fmt.Println("Error reading config:", err)
+		os.Exit(1)
+	}
+	dbURL := cfg.DBURL
+	if dbURL == "" && cfg.Created() {
+		// A new config starts out with a local SQLite file so that gator
+		// works straight away. The URL is saved, so a config without one
+		// means somebody removed it rather than that it is new.
+		dbURL, err = storage.DefaultURL()
+		if err != nil {
+			fmt.Println("Error finding a place for the database:", err)
+			os.Exit(1)
+		}
+		if err := cfg.Set("db_url", dbURL); err != nil {
+			fmt.Println("Error saving config:", err)
+			os.Exit(1)
+		}
+		fmt.Fprintf(os.Stderr, "Created config file %s using the SQLite database %s.\n", cfg.Path(), dbURL)
+	} else if cfg.Created() {
+		fmt.Fprintf(os.Stderr, "Created config file %s.\n", cfg.Path())
+	}
+	if cfg.Insecure() {
+		fmt.Fprintf(os.Stderr, "Warning: %s is readable by other users; run chmod 600 on it.\n", cfg.Path())
+	}
 
+	// config is how a missing db_url gets fixed, so it runs without a
+	// database.
+	isConfig := len(args) > 0 && args[0] == "config"
+	if dbURL == "" && !isConfig {
+		fmt.Printf("Error: profile %s in %s has no db_url; set one with `gator config set db_url <url>` or %s\n",
+			cfg.Profile(), cfg.Path(), config.EnvDBURL)
+		os.Exit(1)
+	}
 	s := &state{
-		db:  dbQueries,
-		cfg: &cfg,
+		cfg:    &cfg,
+		output: output,
+	}
+	if dbURL != "" {
+		db, err := storage.Open(dbURL)
+		if err != nil {
+			fmt.Printf("Error connecting to database: %v\n", err)
+			os.Exit(1)
+		}
+		s.db, s.engine, s.migrator = db.Store, db.Engine, db.Migrator
 	}
 
 	cmds := commands{
@@ -35,25 +77,61 @@ func main() {
 	}
 	cmds.register("login", handlerLogin)
 	cmds.register("register", handlerRegister)
-	cmds.register("reset", handlerReset)
-	cmds.register("users", handlerListUsers)
+	cmds.register("logout", handlerLogout)
+	cmds.register("passwd", middlewareRequire(permRead, handlerPasswd))
+	cmds.register("reset", middlewareRequire(permAdmin, handlerReset))
+	cmds.register("users", middlewareRequire(permAdmin, handlerListUsers))
+	cmds.register("deluser", middlewareRequire(permRead, handlerDeleteUser))
+	cmds.register("renameuser", middlewareRequire(permRead, handlerRenameUser))
+	cmds.register("deactivate", middlewareRequire(permRead, handlerDeactivate))
+	cmds.register("grant", middlewareRequire(permAdmin, handlerGrant))
+	cmds.register("revoke", middlewareRequire(permAdmin, handlerRevoke))
 	cmds.register("agg", handlerAgg)
-	cmds.register("addfeed", middlewareLoggedIn(handlerAddFeed))
-	cmds.register("feeds", handlerFeeds)
-	cmds.register("follow", middlewareLoggedIn(handlerFollow))
-	cmds.register("following", middlewareLoggedIn(handlerFollowing))
-	cmds.register("unfollow", middlewareLoggedIn(handlerUnfollow))
-	cmds.register("browse", middlewareLoggedIn(handlerBrowsePosts))
-
-	if len(os.Args) < 2 {
+	cmds.register("addfeed", middlewareRequire(permWrite, handlerAddFeed))
+	cmds.register("feeds", middlewareRequire(permRead, handlerFeeds))
+	cmds.register("removefeed", middlewareRequire(permWrite, handlerRemoveFeed))
+	cmds.register("renamefeed", middlewareRequire(permWrite, handlerRenameFeed))
+	cmds.register("setfeedurl", middlewareRequire(permWrite, handlerSetFeedURL))
+	cmds.register("follow", middlewareRequire(permWrite, handlerFollow))
+	cmds.register("following", middlewareRequire(permRead, handlerFollowing))
+	cmds.register("unfollow", middlewareRequire(permWrite, handlerUnfollow))
+	cmds.register("tag", middlewareRequire(permWrite, handlerTag))
+	cmds.register("untag", middlewareRequire(permWrite, handlerUntag))
+	cmds.register("import", middlewareRequire(permWrite, handlerImport))
+	cmds.register("export", middlewareRequire(permRead, handlerExport))
+	cmds.register("rule", middlewareRequire(permRead, handlerRule))
+	cmds.register("saved", middlewareRequire(permRead, handlerSaved))
+	cmds.register("digest", middlewareRequire(permRead, handlerDigest))
+	cmds.register("browse", middlewareRequire(permRead, handlerBrowsePosts))
+	cmds.register("search", middlewareRequire(permRead, handlerSearch))
+	cmds.register("tui", middlewareRequire(permRead, handlerTUI))
+	cmds.register("normalize-urls", middlewareRequire(permAdmin, handlerNormalizeURLs))
+	cmds.register("prune", middlewareRequire(permAdmin, handlerPrune))
+	cmds.register("retention", middlewareRequire(permAdmin, handlerRetention))
+	cmds.register("config", handlerConfig)
+	cmds.register("migrate", handlerMigrate)
+	cmds.register("dump", middlewareRequire(permAdmin, handlerDump))
+	cmds.register("restore", handlerRestore)
+	cmds.register("prefs", middlewareRequire(permRead, handlerPrefs))
+
+	if len(args) < 1 {
 		fmt.Println("not enough arguments, expected a command")
 		os.Exit(1)
 	}
 
-	cmdName := os.Args[1]
-	cmdArgs := os.Args[2:]
+	cmdName := args[0]
+	cmdArgs := args[1:]
 	cmd := command{Name: cmdName, Args: cmdArgs}
 
+	// config and migrate are how a new database gets set up, so they must
+	// work before the schema is current.
+	if cmdName != "config" && cmdName != "migrate" {
+		if err := checkSchema(s); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+	}
+
 	if err := cmds.run(s, cmd); err != nil {
 		fmt.Println("Error:", err)
 		os.Exit(1)
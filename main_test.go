@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Grumpster-Dev/gator/internal/config"
+	"github.com/Grumpster-Dev/gator/internal/database"
+	"github.com/Grumpster-Dev/gator/internal/storage"
+	"github.com/google/uuid"
+)
+
+// newTestState returns a state backed by a new, migrated SQLite database
+// and a config file in a temporary directory.
+func newTestState(t *testing.T) *state {
+	t.Helper()
+	t.Setenv(config.EnvUser, "")
+	t.Setenv(config.EnvSessionToken, "")
+	dir := t.TempDir()
+	cfg, err := config.Read(config.Options{Path: filepath.Join(dir, "config.json")})
+	if err != nil {
+		t.Fatalf("reading config: %v", err)
+	}
+	db, err := storage.Open("sqlite:" + filepath.Join(dir, "gator.db"))
+	if err != nil {
+		t.Fatalf("opening database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := db.Migrator.Up(context.Background(), db.Migrator.Latest(), nil); err != nil {
+		t.Fatalf("migrating database: %v", err)
+	}
+	return &state{
+		db:       db.Store,
+		engine:   db.Engine,
+		migrator: db.Migrator,
+		cfg:      &cfg,
+		output:   outputOptions{format: outputTable, location: time.UTC},
+	}
+}
+
+// withStdin answers the prompts of the rest of the test from input, one
+// line per prompt.
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	old := stdin
+	stdin = bufio.NewReader(strings.NewReader(input))
+	t.Cleanup(func() { stdin = old })
+}
+
+// captureStdout runs fn and returns what it printed to stdout.
+func captureStdout(t *testing.T, fn func() error) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	old := os.Stdout
+	os.Stdout = w
+	out := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		out <- string(data)
+	}()
+	err = fn()
+	os.Stdout = old
+	w.Close()
+	printed := <-out
+	if err != nil {
+		t.Fatal(err)
+	}
+	return printed
+}
+
+func createTestFeed(t *testing.T, s *state, name string, addedBy database.User) database.Feed {
+	t.Helper()
+	feed, err := s.db.CreateFeed(context.Background(), database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: time.Now().UTC(),
+		UpdatedAt: time.Now().UTC(),
+		Name:      name,
+		Url:       "https://" + name + ".example.com/feed",
+		AddedBy:   uuid.NullUUID{UUID: addedBy.ID, Valid: true},
+	})
+	if err != nil {
+		t.Fatalf("creating feed %s: %v", name, err)
+	}
+	return feed
+}
+
+func createTestPost(t *testing.T, s *state, feed database.Feed, title string, publishedAt time.Time) database.Post {
+	t.Helper()
+	id := uuid.New()
+	url := feed.Url + "/" + id.String()
+	post, err := s.db.CreatePost(context.Background(), database.CreatePostParams{
+		ID:           id,
+		CreatedAt:    time.Now().UTC(),
+		UpdatedAt:    time.Now().UTC(),
+		Title:        title,
+		Url:          url,
+		PublishedAt:  sql.NullTime{Time: publishedAt, Valid: true},
+		FeedID:       feed.ID,
+		Categories:   []string{},
+		CanonicalUrl: sql.NullString{String: url, Valid: true},
+		ClusterID:    id,
+	})
+	if err != nil {
+		t.Fatalf("creating post %s: %v", title, err)
+	}
+	return post
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+	"time"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputJSONL = "jsonl"
+	outputCSV   = "csv"
+)
+
+// outputOptions controls how list commands print their records. They are
+// global options, accepted anywhere on the command line.
+type outputOptions struct {
+	format    string
+	formatSet bool
+	template  *template.Template
+	// location is the time zone dates are shown in.
+	location *time.Location
+	// extraColumns shows fields tagged `table:"-"` in the table anyway.
+	extraColumns []string
+}
+
+// extractOutputOptions removes --output/-o and --template from args and
+// returns the rest.
+func extractOutputOptions(args []string) (outputOptions, []string, error) {
+	values, rest, err := extractGlobalFlags(args, "output", "o", "template")
+	if err != nil {
+		return outputOptions{}, nil, err
+	}
+	opts := outputOptions{format: outputTable, location: time.Local}
+	if format, ok := values["output"]; ok {
+		opts.format = format
+		opts.formatSet = true
+	}
+	if format, ok := values["o"]; ok {
+		opts.format = format
+		opts.formatSet = true
+	}
+	templateText := values["template"]
+
+	switch opts.format {
+	case outputTable, outputJSON, outputJSONL, outputCSV:
+	default:
+		return outputOptions{}, nil, fmt.Errorf("invalid output format %q: use table, json, jsonl or csv", opts.format)
+	}
+	if templateText != "" {
+		tmpl, err := template.New("output").Parse(templateText)
+		if err != nil {
+			return outputOptions{}, nil, fmt.Errorf("invalid template: %w", err)
+		}
+		opts.template = tmpl
+	}
+	return opts, rest, nil
+}
+
+// printRecords writes records, a slice of structs, to stdout in the
+// selected output format. Column names for table and csv output come from
+// each field's json tag; fields tagged `table:"-"` are left out of the
+// table to keep it readable.
+func (s *state) printRecords(records any) error {
+	return writeRecords(os.Stdout, s.output, records)
+}
+
+func writeRecords(w io.Writer, opts outputOptions, records any) error {
+	rv := reflect.ValueOf(records)
+	if rv.Kind() != reflect.Slice {
+		return fmt.Errorf("records must be a slice, got %T", records)
+	}
+
+	if opts.template != nil || opts.format == outputJSON || opts.format == outputJSONL {
+		rv = inLocation(rv, opts.location)
+	}
+
+	if opts.template != nil {
+		for i := 0; i < rv.Len(); i++ {
+			if err := opts.template.Execute(w, rv.Index(i).Interface()); err != nil {
+				return fmt.Errorf("couldn't render template: %w", err)
+			}
+			fmt.Fprintln(w)
+		}
+		return nil
+	}
+
+	switch opts.format {
+	case outputJSON:
+		if rv.IsNil() {
+			rv = reflect.MakeSlice(rv.Type(), 0, 0)
+		}
+		data, err := json.MarshalIndent(rv.Interface(), "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case outputJSONL:
+		enc := json.NewEncoder(w)
+		for i := 0; i < rv.Len(); i++ {
+			if err := enc.Encode(rv.Index(i).Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	columns := recordColumns(rv.Type().Elem(), opts)
+	if opts.format == outputCSV {
+		cw := csv.NewWriter(w)
+		if err := cw.Write(columnNames(columns)); err != nil {
+			return err
+		}
+		for i := 0; i < rv.Len(); i++ {
+			if err := cw.Write(recordValues(rv.Index(i), columns, opts.location)); err != nil {
+				return err
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(columnNames(columns), "\t")))
+	for i := 0; i < rv.Len(); i++ {
+		fmt.Fprintln(tw, strings.Join(recordValues(rv.Index(i), columns, opts.location), "\t"))
+	}
+	return tw.Flush()
+}
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// inLocation returns a copy of records with their time fields moved to loc,
+// for the formats that print times as they are.
+func inLocation(records reflect.Value, loc *time.Location) reflect.Value {
+	if loc == nil || records.IsNil() {
+		return records
+	}
+	out := reflect.MakeSlice(records.Type(), records.Len(), records.Len())
+	reflect.Copy(out, records)
+	for i := 0; i < out.Len(); i++ {
+		record := out.Index(i)
+		if record.Kind() != reflect.Struct {
+			continue
+		}
+		for j := 0; j < record.NumField(); j++ {
+			field := record.Field(j)
+			if !field.CanSet() {
+				continue
+			}
+			switch {
+			case field.Type() == timeType:
+				field.Set(reflect.ValueOf(field.Interface().(time.Time).In(loc)))
+			case field.Type() == reflect.PointerTo(timeType) && !field.IsNil():
+				t := field.Elem().Interface().(time.Time).In(loc)
+				field.Set(reflect.ValueOf(&t))
+			}
+		}
+	}
+	return out
+}
+
+type recordColumn struct {
+	name  string
+	index int
+}
+
+func recordColumns(t reflect.Type, opts outputOptions) []recordColumn {
+	var columns []recordColumn
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "" {
+			name = field.Name
+		}
+		hidden := opts.format == outputTable && field.Tag.Get("table") == "-" && !slices.Contains(opts.extraColumns, name)
+		if !field.IsExported() || name == "-" || hidden {
+			continue
+		}
+		columns = append(columns, recordColumn{name: name, index: i})
+	}
+	return columns
+}
+
+func columnNames(columns []recordColumn) []string {
+	names := make([]string, len(columns))
+	for i, c := range columns {
+		names[i] = c.name
+	}
+	return names
+}
+
+func recordValues(record reflect.Value, columns []recordColumn, loc *time.Location) []string {
+	values := make([]string, len(columns))
+	for i, c := range columns {
+		values[i] = formatValue(record.Field(c.index), loc)
+	}
+	return values
+}
+
+func formatValue(v reflect.Value, loc *time.Location) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch value := v.Interface().(type) {
+	case time.Time:
+		return value.In(loc).Format(time.RFC3339)
+	case []string:
+		return strings.Join(value, ", ")
+	case fmt.Stringer:
+		return value.String()
+	}
+	return fmt.Sprint(v.Interface())
+}
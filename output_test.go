@@ -0,0 +1,32 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteRecordsTimezone(t *testing.T) {
+	type record struct {
+		Name string     `json:"name"`
+		At   time.Time  `json:"at"`
+		Seen *time.Time `json:"seen"`
+	}
+	at := time.Date(2026, 10, 12, 20, 0, 0, 0, time.UTC)
+	records := []record{{Name: "a", At: at, Seen: &at}, {Name: "b", At: at}}
+	loc := time.FixedZone("UTC+9", 9*60*60)
+
+	for _, format := range []string{outputTable, outputCSV, outputJSON, outputJSONL} {
+		var out bytes.Buffer
+		if err := writeRecords(&out, outputOptions{format: format, location: loc}, records); err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if want := "2026-10-13T05:00:00+09:00"; strings.Count(out.String(), want) != 3 {
+			t.Errorf("%s output doesn't show every time as %s:\n%s", format, want, out.String())
+		}
+	}
+	if records[0].At.Location() != time.UTC || records[0].Seen.Location() != time.UTC {
+		t.Error("writeRecords changed the records it was given")
+	}
+}
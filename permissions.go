@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/Grumpster-Dev/gator/internal/database"
+)
+
+const (
+	roleAdmin    = "admin"
+	roleMember   = "member"
+	roleReadOnly = "read-only"
+)
+
+// roles are ordered from most to least privileged.
+var roles = []string{roleAdmin, roleMember, roleReadOnly}
+
+type permission string
+
+const (
+	// permRead covers reading posts and managing one's own read state and
+	// account.
+	permRead permission = "read"
+	// permWrite covers changing subscriptions, tags, rules and saved
+	// searches.
+	permWrite permission = "write"
+	// permAdmin covers other users' accounts and database-wide operations.
+	permAdmin permission = "admin"
+)
+
+var rolePermissions = map[string][]permission{
+	roleAdmin:    {permRead, permWrite, permAdmin},
+	roleMember:   {permRead, permWrite},
+	roleReadOnly: {permRead},
+}
+
+func hasPermission(user database.User, perm permission) bool {
+	return slices.Contains(rolePermissions[user.Role], perm)
+}
+
+func checkPermission(user database.User, perm permission) error {
+	if !hasPermission(user, perm) {
+		return fmt.Errorf("permission denied: %s users can't do this (needs %s)", user.Role, perm)
+	}
+	return nil
+}
+
+// middlewareRequire is middlewareLoggedIn for handlers that need a
+// permission beyond being logged in.
+func middlewareRequire(perm permission, handler func(s *state, cmd command, user database.User) error) func(*state, command) error {
+	return middlewareLoggedIn(func(s *state, cmd command, user database.User) error {
+		if err := checkPermission(user, perm); err != nil {
+			return err
+		}
+		return handler(s, cmd, user)
+	})
+}
+
+// handlerGrant gives a user a role.
+func handlerGrant(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 2 || !slices.Contains(roles, cmd.Args[1]) {
+		return fmt.Errorf("usage: %s <name> <admin|member|read-only>", cmd.Name)
+	}
+	target, err := s.db.GetUser(context.Background(), cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find user %s: %w", cmd.Args[0], err)
+	}
+	return setRole(s, target, cmd.Args[1])
+}
+
+// handlerRevoke takes a role away, leaving the user with the next role
+// down: admins become members and members become read-only.
+func handlerRevoke(s *state, cmd command, user database.User) error {
+	if len(cmd.Args) != 2 || !slices.Contains(roles, cmd.Args[1]) {
+		return fmt.Errorf("usage: %s <name> <admin|member>", cmd.Name)
+	}
+	target, err := s.db.GetUser(context.Background(), cmd.Args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find user %s: %w", cmd.Args[0], err)
+	}
+	role := cmd.Args[1]
+	if target.Role != role {
+		return fmt.Errorf("user %s is %s, not %s", target.Name, target.Role, role)
+	}
+	next := slices.Index(roles, role) + 1
+	if next >= len(roles) {
+		return fmt.Errorf("user %s already has the least privileged role; use deactivate to block them", target.Name)
+	}
+	return setRole(s, target, roles[next])
+}
+
+func setRole(s *state, target database.User, role string) error {
+	if target.Role == role {
+		fmt.Printf("User %s is already %s.\n", target.Name, role)
+		return nil
+	}
+	if target.Role == roleAdmin {
+		admins, err := s.db.CountActiveAdmins(context.Background())
+		if err != nil {
+			return fmt.Errorf("couldn't count admins: %w", err)
+		}
+		if admins <= 1 {
+			return fmt.Errorf("%s is the last admin; make another user an admin first", target.Name)
+		}
+	}
+	err := s.db.SetUserRole(context.Background(), database.SetUserRoleParams{ID: target.ID, Role: role})
+	if err != nil {
+		return fmt.Errorf("couldn't change role: %w", err)
+	}
+	fmt.Printf("User %s is now %s.\n", target.Name, role)
+	return nil
+}
@@ -7,6 +7,7 @@ import (
 	"html"
 	"io"
 	"net/http"
+	"strings"
 )
 
 type RSSFeed struct {
@@ -19,10 +20,14 @@ type RSSFeed struct {
 }
 
 type RSSItem struct {
-	Title       string `xml:"title"`
-	Link        string `xml:"link"`
-	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	Description string   `xml:"description"`
+	PubDate     string   `xml:"pubDate"`
+	Author      string   `xml:"author"`
+	Creator     string   `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	Content     string   `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Categories  []string `xml:"category"`
 }
 
 func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
@@ -63,6 +68,10 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	for i := 0; i < len(feed.Channel.Item); i++ {
 		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
 		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+		feed.Channel.Item[i].Content = html.UnescapeString(feed.Channel.Item[i].Content)
+		for j, category := range feed.Channel.Item[i].Categories {
+			feed.Channel.Item[i].Categories[j] = strings.TrimSpace(html.UnescapeString(category))
+		}
 	}
 
 	return &feed, nil
@@ -1,8 +1,18 @@
 -- name: CreatePost :one
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, author, content, categories, canonical_url, fingerprint, cluster_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
 RETURNING *;
 
+-- name: DeleteAllPosts :execrows
+DELETE FROM posts;
+
+-- name: FindDuplicatePost :one
+SELECT id, cluster_id FROM posts
+WHERE (canonical_url = sqlc.arg(canonical_url) OR fingerprint = sqlc.narg(fingerprint)::text)
+    AND created_at >= sqlc.arg(since)
+ORDER BY created_at, id
+LIMIT 1;
+
 -- name: GetPostsByUser :many
 SELECT posts.*
 FROM posts
@@ -12,5 +22,101 @@ WHERE feed_follows.user_id = $1
 ORDER BY posts.created_at DESC
 LIMIT $2;
 
+-- name: ListPostURLs :many
+SELECT id, feed_id, url FROM posts
+ORDER BY created_at, id;
+
+-- name: UpdatePostURL :exec
+UPDATE posts SET url = $2, canonical_url = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: BrowsePostsForUser :many
+WITH visible AS (
+    SELECT posts.*, feeds.name AS feed_name, feeds.url AS feed_url,
+        (CASE WHEN sqlc.arg(sort_by)::text = 'fetched' THEN posts.created_at
+            ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamptz AS sort_at,
+        post_matches_rules(feed_follows.user_id, 'highlight', posts.feed_id, posts.title, posts.description, posts.author, posts.categories) AS highlighted,
+        ARRAY(
+            SELECT DISTINCT dup_feeds.name
+            FROM posts dup
+            JOIN feeds dup_feeds ON dup.feed_id = dup_feeds.id
+            JOIN feed_follows dup_follows ON dup_follows.feed_id = dup.feed_id AND dup_follows.user_id = feed_follows.user_id
+            WHERE dup.cluster_id = posts.cluster_id AND dup.feed_id <> posts.feed_id
+        )::text[] AS also_in,
+        row_number() OVER (PARTITION BY posts.cluster_id ORDER BY posts.created_at, posts.id) AS cluster_rank
+    FROM posts
+    JOIN feeds ON posts.feed_id = feeds.id
+    JOIN feed_follows ON feeds.id = feed_follows.feed_id
+    WHERE feed_follows.user_id = sqlc.arg(user_id)
+        AND NOT post_matches_rules(feed_follows.user_id, 'mute', posts.feed_id, posts.title, posts.description, posts.author, posts.categories)
+        AND (sqlc.narg(query)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(query)::text))
+        AND (COALESCE(cardinality(sqlc.arg(feed_ids)::uuid[]), 0) = 0 OR posts.feed_id = ANY(sqlc.arg(feed_ids)::uuid[]))
+        AND (COALESCE(cardinality(sqlc.arg(tags)::text[]), 0) = 0 OR EXISTS (
+            SELECT 1 FROM feed_follow_tags
+            JOIN tags ON feed_follow_tags.tag_id = tags.id
+            WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name = ANY(sqlc.arg(tags)::text[])
+        ))
+        AND (sqlc.narg(author)::text IS NULL OR posts.author ILIKE '%' || sqlc.narg(author)::text || '%')
+        AND (sqlc.narg(since)::timestamptz IS NULL OR (CASE WHEN sqlc.arg(sort_by)::text = 'fetched' THEN posts.created_at
+            ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamptz >= sqlc.narg(since)::timestamptz)
+        AND (sqlc.narg(until)::timestamptz IS NULL OR (CASE WHEN sqlc.arg(sort_by)::text = 'fetched' THEN posts.created_at
+            ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamptz < sqlc.narg(until)::timestamptz)
+)
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author, content, search_vector, categories, canonical_url, fingerprint, cluster_id, feed_name, feed_url, sort_at, highlighted, also_in
+FROM visible
+WHERE (sqlc.narg(after_at)::timestamptz IS NULL OR (sort_at, id) < (sqlc.narg(after_at)::timestamptz, sqlc.narg(after_id)::uuid))
+    -- A story shows once, as its first fetched copy among the posts that
+    -- pass the filters, so filtering out or muting one copy can't hide it.
+    AND (NOT sqlc.arg(collapse_duplicates)::boolean OR cluster_rank = 1)
+ORDER BY sort_at DESC, id DESC
+LIMIT sqlc.arg(post_limit) OFFSET sqlc.arg(post_offset);
+
+-- name: CountUnreadPostsForUser :one
+WITH visible AS (
+    SELECT posts.cluster_id, post_states.read_at,
+        row_number() OVER (PARTITION BY posts.cluster_id ORDER BY posts.created_at, posts.id) AS cluster_rank
+    FROM posts
+    JOIN feeds ON posts.feed_id = feeds.id
+    JOIN feed_follows ON feeds.id = feed_follows.feed_id
+    LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+    WHERE feed_follows.user_id = sqlc.arg(user_id)
+        AND NOT post_matches_rules(feed_follows.user_id, 'mute', posts.feed_id, posts.title, posts.description, posts.author, posts.categories)
+        AND (sqlc.narg(query)::text IS NULL OR posts.search_vector @@ websearch_to_tsquery('english', sqlc.narg(query)::text))
+        AND (COALESCE(cardinality(sqlc.arg(feed_ids)::uuid[]), 0) = 0 OR posts.feed_id = ANY(sqlc.arg(feed_ids)::uuid[]))
+        AND (COALESCE(cardinality(sqlc.arg(tags)::text[]), 0) = 0 OR EXISTS (
+            SELECT 1 FROM feed_follow_tags
+            JOIN tags ON feed_follow_tags.tag_id = tags.id
+            WHERE feed_follow_tags.feed_follow_id = feed_follows.id AND tags.name = ANY(sqlc.arg(tags)::text[])
+        ))
+        AND (sqlc.narg(author)::text IS NULL OR posts.author ILIKE '%' || sqlc.narg(author)::text || '%')
+        AND (sqlc.narg(since)::timestamptz IS NULL OR (CASE WHEN sqlc.arg(sort_by)::text = 'fetched' THEN posts.created_at
+            ELSE COALESCE(posts.published_at, posts.created_at) END)::timestamptz >= sqlc.narg(since)::timestamptz)
+)
+SELECT COUNT(*)
+FROM visible
+WHERE read_at IS NULL
+    -- Count the copies BrowsePostsForUser would show.
+    AND (NOT sqlc.arg(collapse_duplicates)::boolean OR cluster_rank = 1);
 
+-- name: ListExpiredPosts :many
+SELECT ranked.id,
+    (EXISTS (
+        SELECT 1 FROM post_states
+        WHERE post_states.post_id = ranked.id AND post_states.starred_at IS NOT NULL
+    ) OR EXISTS (
+        SELECT 1 FROM feed_follows
+        LEFT JOIN post_states ON post_states.post_id = ranked.id AND post_states.user_id = feed_follows.user_id
+        WHERE feed_follows.feed_id = sqlc.arg(feed_id) AND post_states.read_at IS NULL
+    ))::boolean AS protected
+FROM (
+    SELECT id, COALESCE(published_at, created_at) AS posted_at,
+        row_number() OVER (ORDER BY COALESCE(published_at, created_at) DESC, id DESC) AS post_rank
+    FROM posts
+    WHERE feed_id = sqlc.arg(feed_id)
+) ranked
+WHERE ranked.posted_at < sqlc.narg(cutoff)::timestamptz
+    OR ranked.post_rank > sqlc.narg(max_posts)::bigint
+ORDER BY ranked.posted_at, ranked.id;
 
+-- name: DeletePosts :execrows
+DELETE FROM posts WHERE id = ANY(sqlc.arg(ids)::uuid[]);
@@ -17,7 +17,7 @@ INNER JOIN feeds ON inserted_feed_follow.feed_id = feeds.id
 INNER JOIN users ON inserted_feed_follow.user_id = users.id;
 
 -- name: GetFeedFollowsForUser :many
-SELECT feed_follows.*, feeds.name AS feed_name, users.name AS user_name
+SELECT feed_follows.*, feeds.name AS feed_name, feeds.url AS feed_url, users.name AS user_name
 FROM feed_follows
 INNER JOIN feeds ON feed_follows.feed_id = feeds.id
 INNER JOIN users ON feed_follows.user_id = users.id
@@ -27,3 +27,13 @@ WHERE feed_follows.user_id = $1;
 DELETE FROM feed_follows
 WHERE id = $1 AND created_at = $2;
 
+-- name: GetFeedFollowForUser :one
+SELECT * FROM feed_follows
+WHERE user_id = $1 AND feed_id = $2;
+
+-- name: GetFeedFollowerNames :many
+SELECT users.name
+FROM feed_follows
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.feed_id = $1
+ORDER BY users.name;
@@ -1,5 +1,5 @@
 -- name: CreateFeed :one
-INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
+INSERT INTO feeds (id, created_at, updated_at, name, url, added_by)
 VALUES (
     $1,
     $2,
@@ -16,16 +16,19 @@ SELECT * FROM feeds WHERE name = $1;
 -- name: DeleteFeed :exec
 DELETE FROM feeds WHERE id = $1;
 
+-- name: DeleteAllFeeds :execrows
+DELETE FROM feeds;
+
 -- name: GetFeeds :many
 SELECT * FROM feeds;
 
--- name: GetFeedsByUserID :many
-SELECT * FROM feeds WHERE user_id = $1;
+-- name: GetFeedsAddedBy :many
+SELECT * FROM feeds WHERE added_by = $1;
 
 -- name: GetFeedsByUserName :many
-SELECT feeds.name, feeds.url, users.name AS user_name
+SELECT feeds.*, users.name AS user_name
 FROM feeds
-JOIN users ON feeds.user_id = users.id;
+LEFT JOIN users ON feeds.added_by = users.id;
 
 -- name: GetFeedByURL :one
 SELECT * FROM feeds WHERE url = $1;
@@ -37,7 +40,28 @@ WHERE id = $1;
 
 -- name: GetNextFeedToFetch :one
 SELECT * FROM feeds
+WHERE EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
 ORDER BY last_fetched_at ASC NULLS FIRST
 LIMIT 1;
 
 
+-- name: RenameFeed :exec
+UPDATE feeds SET name = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateFeedURL :exec
+UPDATE feeds SET url = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteUnusedFeeds :execrows
+DELETE FROM feeds
+WHERE NOT EXISTS (SELECT 1 FROM feed_follows WHERE feed_follows.feed_id = feeds.id)
+    AND NOT EXISTS (
+        SELECT 1 FROM posts
+        JOIN post_states ON post_states.post_id = posts.id
+        WHERE posts.feed_id = feeds.id AND post_states.starred_at IS NOT NULL
+    );
+
+-- name: SetFeedRetention :exec
+UPDATE feeds SET retention_days = $2, retention_max_posts = $3, updated_at = NOW()
+WHERE id = $1;
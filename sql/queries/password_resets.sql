@@ -0,0 +1,17 @@
+-- name: UpsertPasswordReset :exec
+INSERT INTO password_resets (user_id, token_hash, created_at, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id) DO UPDATE
+SET token_hash = EXCLUDED.token_hash,
+    created_at = EXCLUDED.created_at,
+    expires_at = EXCLUDED.expires_at;
+
+-- name: HasPasswordReset :one
+SELECT EXISTS (
+    SELECT 1 FROM password_resets
+    WHERE user_id = $1 AND expires_at > NOW()
+);
+
+-- name: UsePasswordReset :execrows
+DELETE FROM password_resets
+WHERE user_id = $1 AND token_hash = $2 AND expires_at > NOW();
@@ -0,0 +1,18 @@
+-- name: CreatePostRule :one
+INSERT INTO post_rules (id, created_at, updated_at, user_id, feed_id, field, match_type, pattern, action)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING *;
+
+-- name: GetPostRulesForUser :many
+SELECT post_rules.*, feeds.name AS feed_name
+FROM post_rules
+LEFT JOIN feeds ON post_rules.feed_id = feeds.id
+WHERE post_rules.user_id = $1
+ORDER BY post_rules.created_at;
+
+-- name: DeletePostRule :execrows
+DELETE FROM post_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: CheckRulePattern :one
+SELECT rule_matches(sqlc.arg(match_type)::text, sqlc.arg(pattern)::text, '')::boolean AS matches;
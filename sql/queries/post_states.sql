@@ -0,0 +1,42 @@
+-- name: MarkPostRead :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at)
+VALUES ($1, $2, NOW(), NOW(), NOW())
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET read_at = COALESCE(post_states.read_at, NOW()), updated_at = NOW();
+
+-- name: MarkPostUnread :exec
+UPDATE post_states SET read_at = NULL, updated_at = NOW()
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: SetPostStarred :exec
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, starred_at)
+VALUES (
+    sqlc.arg(user_id),
+    sqlc.arg(post_id),
+    NOW(),
+    NOW(),
+    CASE WHEN sqlc.arg(starred)::boolean THEN NOW() END
+)
+ON CONFLICT (user_id, post_id) DO UPDATE
+SET starred_at = CASE WHEN sqlc.arg(starred)::boolean THEN COALESCE(post_states.starred_at, NOW()) END,
+    updated_at = NOW();
+
+-- name: GetFeedsWithUnreadCounts :many
+SELECT feeds.id, feeds.name, feeds.url,
+    COUNT(posts.id) FILTER (WHERE post_states.read_at IS NULL) AS unread_count
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+LEFT JOIN posts ON posts.feed_id = feeds.id
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = feed_follows.user_id
+WHERE feed_follows.user_id = $1
+GROUP BY feeds.id, feeds.name, feeds.url
+ORDER BY feeds.name;
+
+-- name: GetPostsWithStateForFeed :many
+SELECT posts.id, posts.title, posts.url, posts.description, posts.content, posts.author,
+    posts.published_at, posts.created_at, post_states.read_at, post_states.starred_at
+FROM posts
+LEFT JOIN post_states ON post_states.post_id = posts.id AND post_states.user_id = sqlc.arg(user_id)
+WHERE posts.feed_id = sqlc.arg(feed_id)
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC, posts.id DESC
+LIMIT sqlc.arg(post_limit);
@@ -0,0 +1,30 @@
+-- name: RestoreUser :exec
+INSERT INTO users (id, created_at, updated_at, name, password_hash, deactivated_at, role)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: RestoreFeed :exec
+INSERT INTO feeds (id, created_at, updated_at, name, url, added_by, last_fetched_at, retention_days, retention_max_posts)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9);
+
+-- name: GetTag :one
+SELECT * FROM tags
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetPostIDByFeedURL :one
+SELECT id FROM posts
+WHERE feed_id = $1 AND url = $2;
+
+-- name: RestorePostState :execrows
+INSERT INTO post_states (user_id, post_id, created_at, updated_at, read_at, starred_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT DO NOTHING;
+
+-- name: RestorePostRule :execrows
+INSERT INTO post_rules (id, created_at, updated_at, user_id, feed_id, field, match_type, pattern, action)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+ON CONFLICT DO NOTHING;
+
+-- name: RestoreUserPreference :execrows
+INSERT INTO user_preferences (user_id, key, value, updated_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,17 @@
+-- name: CreateSavedSearch :one
+INSERT INTO saved_searches (id, created_at, updated_at, user_id, name, query, feed_ids, tags, author, window_seconds)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+RETURNING *;
+
+-- name: GetSavedSearch :one
+SELECT * FROM saved_searches
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetSavedSearchesForUser :many
+SELECT * FROM saved_searches
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: DeleteSavedSearch :execrows
+DELETE FROM saved_searches
+WHERE user_id = $1 AND name = $2;
@@ -0,0 +1,16 @@
+-- name: SearchPostsForUser :many
+SELECT posts.id, posts.title, posts.url, posts.published_at, posts.created_at, feeds.name AS feed_name,
+    ts_rank(posts.search_vector, websearch_to_tsquery('english', sqlc.arg(query)::text)) AS rank,
+    ts_headline('english', coalesce(nullif(posts.description, ''), posts.content, posts.title),
+        websearch_to_tsquery('english', sqlc.arg(query)::text),
+        'StartSel=*, StopSel=*, MaxWords=35, MinWords=15, MaxFragments=2') AS snippet,
+    post_matches_rules(feed_follows.user_id, 'highlight', posts.feed_id, posts.title, posts.description, posts.author, posts.categories) AS highlighted
+FROM posts
+JOIN feeds ON posts.feed_id = feeds.id
+JOIN feed_follows ON feeds.id = feed_follows.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+    AND NOT post_matches_rules(feed_follows.user_id, 'mute', posts.feed_id, posts.title, posts.description, posts.author, posts.categories)
+    AND posts.search_vector @@ websearch_to_tsquery('english', sqlc.arg(query)::text)
+    AND (sqlc.narg(feed_id)::uuid IS NULL OR posts.feed_id = sqlc.narg(feed_id)::uuid)
+ORDER BY rank DESC, posts.id DESC
+LIMIT sqlc.arg(post_limit);
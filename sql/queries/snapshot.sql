@@ -0,0 +1,32 @@
+-- name: ListFeedFollows :many
+SELECT * FROM feed_follows
+ORDER BY created_at, id;
+
+-- name: ListPosts :many
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, author, content, categories, canonical_url, fingerprint, cluster_id
+FROM posts
+ORDER BY created_at, id;
+
+-- name: ListPostStates :many
+SELECT * FROM post_states
+ORDER BY user_id, post_id;
+
+-- name: ListTags :many
+SELECT * FROM tags
+ORDER BY created_at, id;
+
+-- name: ListFeedFollowTags :many
+SELECT * FROM feed_follow_tags
+ORDER BY feed_follow_id, tag_id;
+
+-- name: ListPostRules :many
+SELECT * FROM post_rules
+ORDER BY created_at, id;
+
+-- name: ListSavedSearches :many
+SELECT * FROM saved_searches
+ORDER BY created_at, id;
+
+-- name: ListUserPreferences :many
+SELECT * FROM user_preferences
+ORDER BY user_id, key;
@@ -0,0 +1,35 @@
+-- name: UpsertTag :one
+INSERT INTO tags (id, created_at, updated_at, user_id, name)
+VALUES (
+    $1,
+    $2,
+    $3,
+    $4,
+    $5
+)
+ON CONFLICT (user_id, name) DO UPDATE SET updated_at = EXCLUDED.updated_at
+RETURNING *;
+
+-- name: AddFollowTag :execrows
+INSERT INTO feed_follow_tags (feed_follow_id, tag_id, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveFollowTag :execrows
+DELETE FROM feed_follow_tags
+USING tags
+WHERE feed_follow_tags.tag_id = tags.id
+    AND feed_follow_tags.feed_follow_id = $1
+    AND tags.name = $2;
+
+-- name: DeleteUnusedTags :exec
+DELETE FROM tags
+WHERE user_id = $1
+    AND NOT EXISTS (SELECT 1 FROM feed_follow_tags WHERE feed_follow_tags.tag_id = tags.id);
+
+-- name: GetFollowTagsForUser :many
+SELECT feed_follow_tags.feed_follow_id, tags.name
+FROM feed_follow_tags
+JOIN tags ON feed_follow_tags.tag_id = tags.id
+WHERE tags.user_id = $1
+ORDER BY tags.name;
@@ -1,10 +1,12 @@
 -- name: CreateUser :one
-INSERT INTO users (id, created_at, updated_at, name)
+INSERT INTO users (id, created_at, updated_at, name, password_hash, role)
 VALUES (
     $1,
     $2,
     $3,
-    $4
+    $4,
+    $5,
+    $6
 )
 RETURNING *;
 
@@ -17,4 +19,32 @@ DELETE FROM users;
 -- name: GetUsers :many
 SELECT * FROM users;
 
+-- name: SetUserPassword :exec
+UPDATE users SET password_hash = $2, updated_at = NOW()
+WHERE id = $1;
 
+-- name: CountUsers :one
+SELECT COUNT(*) FROM users;
+
+-- name: DeleteUser :execrows
+DELETE FROM users WHERE id = $1;
+
+-- name: RenameUser :exec
+UPDATE users SET name = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetUserDeactivated :exec
+UPDATE users SET deactivated_at = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CountActiveAdmins :one
+SELECT COUNT(*) FROM users
+WHERE role = 'admin' AND deactivated_at IS NULL;
+
+-- name: SetUserRole :exec
+UPDATE users SET role = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: CountUsersWithPassword :one
+SELECT COUNT(*) FROM users
+WHERE password_hash IS NOT NULL AND deactivated_at IS NULL;
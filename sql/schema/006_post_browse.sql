@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN author TEXT;
+
+CREATE INDEX posts_feed_id_published_at_idx ON posts (feed_id, published_at);
+CREATE INDEX posts_feed_id_created_at_idx ON posts (feed_id, created_at);
+
+-- +goose Down
+DROP INDEX posts_feed_id_created_at_idx;
+DROP INDEX posts_feed_id_published_at_idx;
+
+ALTER TABLE posts
+DROP COLUMN author;
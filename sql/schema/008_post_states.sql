@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE post_states (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    read_at TIMESTAMP,
+    starred_at TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE INDEX post_states_post_id_idx ON post_states (post_id);
+
+-- +goose Down
+DROP TABLE post_states;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE tags (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE feed_follow_tags (
+    feed_follow_id UUID NOT NULL REFERENCES feed_follows(id) ON DELETE CASCADE,
+    tag_id UUID NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (feed_follow_id, tag_id)
+);
+
+CREATE INDEX feed_follow_tags_tag_id_idx ON feed_follow_tags (tag_id);
+
+-- +goose Down
+DROP TABLE feed_follow_tags;
+DROP TABLE tags;
@@ -0,0 +1,65 @@
+-- +goose Up
+ALTER TABLE posts
+ADD COLUMN categories TEXT[] NOT NULL DEFAULT '{}';
+
+CREATE TABLE post_rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id UUID REFERENCES feeds(id) ON DELETE CASCADE,
+    field TEXT NOT NULL CHECK (field IN ('any', 'title', 'description', 'author', 'category')),
+    match_type TEXT NOT NULL CHECK (match_type IN ('keyword', 'regex')),
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('mute', 'highlight'))
+);
+
+CREATE INDEX post_rules_user_id_idx ON post_rules (user_id);
+
+-- +goose StatementBegin
+CREATE FUNCTION rule_matches(match_type TEXT, pattern TEXT, value TEXT) RETURNS BOOLEAN
+LANGUAGE sql IMMUTABLE AS $$
+    SELECT COALESCE(
+        CASE WHEN match_type = 'regex' THEN value ~* pattern
+            ELSE strpos(lower(value), lower(pattern)) > 0 END,
+        false
+    )
+$$;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION post_matches_rules(
+    rule_user_id UUID,
+    rule_action TEXT,
+    post_feed_id UUID,
+    post_title TEXT,
+    post_description TEXT,
+    post_author TEXT,
+    post_categories TEXT[]
+) RETURNS BOOLEAN
+LANGUAGE sql STABLE AS $$
+    SELECT EXISTS (
+        SELECT 1 FROM post_rules r
+        WHERE r.user_id = rule_user_id
+            AND r.action = rule_action
+            AND (r.feed_id IS NULL OR r.feed_id = post_feed_id)
+            AND (
+                (r.field IN ('any', 'title') AND rule_matches(r.match_type, r.pattern, post_title))
+                OR (r.field IN ('any', 'description') AND rule_matches(r.match_type, r.pattern, post_description))
+                OR (r.field IN ('any', 'author') AND rule_matches(r.match_type, r.pattern, post_author))
+                OR (r.field IN ('any', 'category') AND EXISTS (
+                    SELECT 1 FROM unnest(post_categories) AS category
+                    WHERE rule_matches(r.match_type, r.pattern, category)
+                ))
+            )
+    )
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION post_matches_rules(UUID, TEXT, UUID, TEXT, TEXT, TEXT, TEXT[]);
+DROP FUNCTION rule_matches(TEXT, TEXT, TEXT);
+DROP TABLE post_rules;
+
+ALTER TABLE posts
+DROP COLUMN categories;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE saved_searches (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    query TEXT,
+    feed_ids UUID[] NOT NULL DEFAULT '{}',
+    tags TEXT[] NOT NULL DEFAULT '{}',
+    author TEXT,
+    window_seconds BIGINT,
+    UNIQUE(user_id, name)
+);
+
+-- +goose Down
+DROP TABLE saved_searches;
@@ -0,0 +1,36 @@
+-- +goose Up
+ALTER TABLE posts
+DROP CONSTRAINT posts_url_key;
+
+ALTER TABLE posts
+ADD CONSTRAINT posts_feed_id_url_key UNIQUE (feed_id, url);
+
+ALTER TABLE posts
+ADD COLUMN canonical_url TEXT,
+ADD COLUMN fingerprint TEXT,
+ADD COLUMN cluster_id UUID;
+
+UPDATE posts SET canonical_url = url, cluster_id = id;
+
+ALTER TABLE posts
+ALTER COLUMN cluster_id SET NOT NULL;
+
+CREATE INDEX posts_canonical_url_idx ON posts (canonical_url);
+CREATE INDEX posts_fingerprint_idx ON posts (fingerprint);
+CREATE INDEX posts_cluster_id_idx ON posts (cluster_id);
+
+-- +goose Down
+DROP INDEX posts_cluster_id_idx;
+DROP INDEX posts_fingerprint_idx;
+DROP INDEX posts_canonical_url_idx;
+
+ALTER TABLE posts
+DROP COLUMN cluster_id,
+DROP COLUMN fingerprint,
+DROP COLUMN canonical_url;
+
+ALTER TABLE posts
+DROP CONSTRAINT posts_feed_id_url_key;
+
+ALTER TABLE posts
+ADD CONSTRAINT posts_url_key UNIQUE (url);
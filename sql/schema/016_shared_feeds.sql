@@ -0,0 +1,14 @@
+-- +goose Up
+ALTER TABLE feeds RENAME COLUMN user_id TO added_by;
+ALTER TABLE feeds ALTER COLUMN added_by DROP NOT NULL;
+ALTER TABLE feeds DROP CONSTRAINT feeds_user_id_fkey;
+ALTER TABLE feeds ADD CONSTRAINT feeds_added_by_fkey
+    FOREIGN KEY (added_by) REFERENCES users(id) ON DELETE SET NULL;
+
+-- +goose Down
+DELETE FROM feeds WHERE added_by IS NULL;
+ALTER TABLE feeds DROP CONSTRAINT feeds_added_by_fkey;
+ALTER TABLE feeds ALTER COLUMN added_by SET NOT NULL;
+ALTER TABLE feeds RENAME COLUMN added_by TO user_id;
+ALTER TABLE feeds ADD CONSTRAINT feeds_user_id_fkey
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE user_preferences (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+-- +goose Down
+DROP TABLE user_preferences;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN retention_days INTEGER,
+ADD COLUMN retention_max_posts INTEGER;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN retention_max_posts,
+DROP COLUMN retention_days;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- Expiry is compared with NOW(), so it has to carry its zone. Databases
+-- created from an early copy of 013 already have TIMESTAMPTZ.
+-- +goose StatementBegin
+DO $$
+BEGIN
+    IF (SELECT data_type FROM information_schema.columns
+        WHERE table_name = 'sessions' AND column_name = 'expires_at') = 'timestamp without time zone' THEN
+        ALTER TABLE sessions
+        ALTER COLUMN expires_at TYPE TIMESTAMPTZ USING expires_at AT TIME ZONE 'UTC';
+    END IF;
+END
+$$;
+-- +goose StatementEnd
+
+-- +goose Down
+ALTER TABLE sessions
+ALTER COLUMN expires_at TYPE TIMESTAMP USING expires_at AT TIME ZONE 'UTC';
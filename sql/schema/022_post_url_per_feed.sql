@@ -0,0 +1,13 @@
+-- +goose Up
+COMMENT ON CONSTRAINT posts_feed_id_url_key ON posts IS
+    'post URLs are unique per feed; the same post in other feeds is clustered instead (012)';
+
+-- +goose Down
+-- 012's Down restores UNIQUE (url), which fails once two feeds carry the
+-- same post, so keep only the first copy of each.
+DELETE FROM posts
+USING posts AS first
+WHERE posts.url = first.url
+    AND (posts.created_at, posts.id) > (first.created_at, first.id);
+
+COMMENT ON CONSTRAINT posts_feed_id_url_key ON posts IS NULL;
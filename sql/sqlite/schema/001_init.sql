@@ -0,0 +1,165 @@
+-- +goose Up
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT NOT NULL UNIQUE,
+    password_hash TEXT,
+    deactivated_at TIMESTAMP,
+    role TEXT NOT NULL DEFAULT 'member'
+        CHECK (role IN ('admin', 'member', 'read-only'))
+);
+
+CREATE TABLE feeds (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    added_by TEXT REFERENCES users(id) ON DELETE SET NULL,
+    last_fetched_at TIMESTAMP,
+    retention_days INTEGER,
+    retention_max_posts INTEGER
+);
+
+CREATE TABLE feed_follows (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id TEXT NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    UNIQUE(user_id, feed_id)
+);
+
+-- Categories are a JSON array of strings. seq gives posts_fts a stable
+-- docid: VACUUM may renumber an implicit rowid.
+CREATE TABLE posts (
+    seq INTEGER PRIMARY KEY,
+    id TEXT NOT NULL UNIQUE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT,
+    published_at TIMESTAMP,
+    feed_id TEXT NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    author TEXT,
+    content TEXT,
+    categories TEXT NOT NULL DEFAULT '[]',
+    canonical_url TEXT,
+    fingerprint TEXT,
+    cluster_id TEXT NOT NULL,
+    UNIQUE(feed_id, url)
+);
+
+CREATE INDEX posts_feed_id_published_at_idx ON posts (feed_id, published_at);
+CREATE INDEX posts_feed_id_created_at_idx ON posts (feed_id, created_at);
+CREATE INDEX posts_canonical_url_idx ON posts (canonical_url);
+CREATE INDEX posts_fingerprint_idx ON posts (fingerprint);
+CREATE INDEX posts_cluster_id_idx ON posts (cluster_id);
+
+CREATE VIRTUAL TABLE posts_fts USING fts4(title, description, content, tokenize=porter);
+
+CREATE TRIGGER posts_fts_insert AFTER INSERT ON posts BEGIN
+    INSERT INTO posts_fts (docid, title, description, content)
+    VALUES (new.seq, new.title, new.description, new.content);
+END;
+
+CREATE TRIGGER posts_fts_update AFTER UPDATE OF title, description, content ON posts BEGIN
+    UPDATE posts_fts SET title = new.title, description = new.description, content = new.content
+    WHERE docid = old.seq;
+END;
+
+CREATE TRIGGER posts_fts_delete AFTER DELETE ON posts BEGIN
+    DELETE FROM posts_fts WHERE docid = old.seq;
+END;
+
+CREATE TABLE post_states (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id TEXT NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    read_at TIMESTAMP,
+    starred_at TIMESTAMP,
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE INDEX post_states_post_id_idx ON post_states (post_id);
+
+CREATE TABLE tags (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE feed_follow_tags (
+    feed_follow_id TEXT NOT NULL REFERENCES feed_follows(id) ON DELETE CASCADE,
+    tag_id TEXT NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (feed_follow_id, tag_id)
+);
+
+CREATE INDEX feed_follow_tags_tag_id_idx ON feed_follow_tags (tag_id);
+
+CREATE TABLE post_rules (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id TEXT REFERENCES feeds(id) ON DELETE CASCADE,
+    field TEXT NOT NULL CHECK (field IN ('any', 'title', 'description', 'author', 'category')),
+    match_type TEXT NOT NULL CHECK (match_type IN ('keyword', 'regex')),
+    pattern TEXT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('mute', 'highlight'))
+);
+
+CREATE INDEX post_rules_user_id_idx ON post_rules (user_id);
+
+-- feed_ids and tags are JSON arrays.
+CREATE TABLE saved_searches (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    query TEXT,
+    feed_ids TEXT NOT NULL DEFAULT '[]',
+    tags TEXT NOT NULL DEFAULT '[]',
+    author TEXT,
+    window_seconds INTEGER,
+    UNIQUE(user_id, name)
+);
+
+CREATE TABLE sessions (
+    token_hash TEXT PRIMARY KEY,
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX sessions_user_id_idx ON sessions (user_id);
+
+CREATE TABLE user_preferences (
+    user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key TEXT NOT NULL,
+    value TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, key)
+);
+
+-- +goose Down
+DROP TABLE user_preferences;
+DROP TABLE sessions;
+DROP TABLE saved_searches;
+DROP TABLE post_rules;
+DROP TABLE feed_follow_tags;
+DROP TABLE tags;
+DROP TABLE post_states;
+DROP TABLE posts_fts;
+DROP TABLE posts;
+DROP TABLE feed_follows;
+DROP TABLE feeds;
+DROP TABLE users;
@@ -0,0 +1,9 @@
+// Package schema embeds the migrations for SQLite databases. SQLite starts
+// from the schema Postgres reached through sql/schema, so its versions are
+// numbered independently.
+package schema
+
+import "embed"
+
+//go:embed *.sql
+var FS embed.FS